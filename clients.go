@@ -0,0 +1,53 @@
+package gotoggl
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TogglClient contains the data returned for a single client, meaning a
+// customer that projects are billed to. It is not called Client to avoid
+// confusion with the API client.
+type TogglClient struct {
+	Id          int
+	WorkspaceId int `json:"wid"`
+	Name        string
+	Notes       string
+	At          time.Time
+}
+
+// ClientsService accesses /clients
+type ClientsService struct {
+	client *Client
+}
+
+// List returns the clients in a workspace
+func (cs *ClientsService) List(workspaceId int) ([]TogglClient, error) {
+	return cs.ListContext(context.Background(), workspaceId)
+}
+
+// ListContext is like List with a context.
+func (cs *ClientsService) ListContext(ctx context.Context, workspaceId int) ([]TogglClient, error) {
+	clients := []TogglClient{}
+	err := cs.client.getData(ctx, fmt.Sprintf("workspaces/%d/clients", workspaceId), &clients)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get clients: %w\n", err)
+	}
+	return clients, nil
+}
+
+// Get returns details of a single client
+func (cs *ClientsService) Get(id int) (TogglClient, error) {
+	return cs.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get with a context.
+func (cs *ClientsService) GetContext(ctx context.Context, id int) (TogglClient, error) {
+	client := TogglClient{}
+	err := cs.client.getData(ctx, fmt.Sprintf("clients/%d", id), &client)
+	if err != nil {
+		return TogglClient{}, fmt.Errorf("Couldn't get client %v: %w\n", id, err)
+	}
+	return client, nil
+}
@@ -2,12 +2,22 @@
 package gotoggl
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,24 +30,132 @@ const (
 	TogglApi   = "https://www.toggl.com/api/v8/"
 	ReportsApi = "https://toggl.com/reports/api/v2/"
 	UserAgent  = "github.com/roessland/gotoggl"
+
+	// DefaultMaxConcurrency is the default for Client.MaxConcurrency.
+	DefaultMaxConcurrency = 4
+
+	// DefaultTimeout is the request timeout of clients made by NewClient.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultMaxRetries is the default for Client.MaxRetries.
+	DefaultMaxRetries = 3
+
+	// TogglRequestInterval is the spacing Toggl asks clients to keep between
+	// requests, about one per second per API token. Set it as
+	// Client.MinRequestInterval to stay under the limit instead of relying
+	// on retries after 429.
+	TogglRequestInterval = time.Second
 )
 
+// ErrPremiumRequired is returned when Toggl answers 402 Payment Required,
+// which it does when a free workspace uses a premium-only feature. Check for
+// it with errors.Is.
+var ErrPremiumRequired = errors.New("Toggl premium workspace required")
+
+// ErrNotFound is returned when Toggl answers 404 Not Found, for example for a
+// time entry that does not exist or was already deleted. Check for it with
+// errors.Is.
+var ErrNotFound = errors.New("Not found")
+
+// APIError is returned by GET, POST, PUT and DELETE when Toggl answers with
+// a status outside 2xx. Body is the raw response body, which is often plain
+// text or HTML rather than JSON.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v to %v got status %v: %v", e.Method, e.URL, e.Status, e.Body)
+}
+
+// Is makes errors.Is match a 404 APIError to ErrNotFound and a 402 APIError
+// to ErrPremiumRequired.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrPremiumRequired:
+		return e.StatusCode == http.StatusPaymentRequired
+	}
+	return false
+}
+
+// IsNotFound reports whether err means the requested object does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsRateLimited reports whether err is a 429 Too Many Requests from Toggl.
+func IsRateLimited(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// ErrRangeTruncated is returned by TimeEntriesService.Range when the range
+// holds more entries than Toggl returns at once.
+var ErrRangeTruncated = errors.New("Time entry range truncated")
+
+// ErrAlreadyStopped is returned by TimeEntriesService.Stop for an entry that
+// is not running.
+var ErrAlreadyStopped = errors.New("Time entry is already stopped")
+
+// ErrConflict is returned by TimeEntriesService.UpdateIfUnmodified when the
+// entry was modified since the caller read it.
+var ErrConflict = errors.New("Time entry was modified since it was read")
+
+// ErrNotRunning is returned by TimeEntriesService.Current and StopCurrent when
+// no time entry is running.
+var ErrNotRunning = errors.New("No time entry is running")
+
 // Duration encapsulates the standard Duration in an anonymous field. Toggl
 // returns durations in seconds, but time.Duration uses nanoseconds. Therefore
 // we have to implement a custom UnmarshalJSON.
 type Duration struct{ time.Duration }
 
 // UnmarshalJSON loads a Toggl duration into a Go duration. Toggl durations are
-// given in seconds.
+// given in seconds, usually as a number but sometimes as a quoted string. A
+// running time entry has a negative duration, which is minus its start time
+// as a Unix timestamp; that is loaded as the time elapsed since the start.
 func (d *Duration) UnmarshalJSON(data []byte) error {
-	seconds, err := strconv.ParseInt(string(data), 10, 64)
+	if string(data) == "null" {
+		return nil
+	}
+	seconds, err := strconv.ParseInt(unquoteNumber(data), 10, 64)
 	if err != nil {
-		fmt.Errorf("Couldn't unmarshal toggl.Duration: %v\n", err)
+		return fmt.Errorf("Couldn't unmarshal toggl.Duration: %w\n", err)
+	}
+	if seconds < 0 {
+		d.Duration = time.Since(time.Unix(-seconds, 0)).Truncate(time.Second)
+		return nil
 	}
 	d.Duration = time.Duration(seconds * int64(time.Second))
 	return nil
 }
 
+// unquoteNumber returns data as a string, without the quotes Toggl puts
+// around numbers in some payloads.
+func unquoteNumber(data []byte) string {
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		return string(data[1 : len(data)-1])
+	}
+	return string(data)
+}
+
+// MarshalJSON writes the duration in whole seconds, like Toggl sends it.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, d.Seconds(), 10), nil
+}
+
+// Seconds returns the duration in whole seconds, which is how Toggl sends and
+// expects it. It shadows time.Duration.Seconds, which returns a float64.
+func (d Duration) Seconds() int64 {
+	return int64(d.Duration / time.Second)
+}
+
 // TimeEntry contains the data returned for a single time entry.
 type TimeEntry struct {
 	Id          int
@@ -53,7 +171,128 @@ type TimeEntry struct {
 	UserId      int    `json:"uid"`
 	CreatedWith string `json:"created_with"`
 	Tags        []string
-	At          string
+
+	// At is when the entry was last modified. It used to be the raw string
+	// Toggl sent; use At.Format(time.RFC3339) where that string is needed.
+	// It is zero if Toggl left it out.
+	At time.Time
+
+	// RawDuration is the duration exactly as Toggl sent it, in seconds. For
+	// a running entry it is minus the start time as a Unix timestamp.
+	RawDuration int64 `json:"-"`
+}
+
+// UnmarshalJSON decodes a time entry from either the v8 or the v9 API. v8
+// uses wid and pid while v9 uses workspace_id and project_id. The duration is
+// stored both as Duration and untouched as RawDuration.
+func (te *TimeEntry) UnmarshalJSON(data []byte) error {
+	type timeEntry TimeEntry
+	aux := struct {
+		*timeEntry
+		Duration      json.RawMessage
+		At            *string
+		V9WorkspaceId int `json:"workspace_id"`
+		V9ProjectId   int `json:"project_id"`
+	}{timeEntry: (*timeEntry)(te)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.At != nil && *aux.At != "" {
+		at, err := time.Parse(time.RFC3339, *aux.At)
+		if err != nil {
+			return fmt.Errorf("Couldn't unmarshal time entry at: %w\n", err)
+		}
+		te.At = at
+	}
+	if len(aux.Duration) > 0 && string(aux.Duration) != "null" {
+		raw, err := strconv.ParseInt(unquoteNumber(aux.Duration), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Couldn't unmarshal time entry duration: %w\n", err)
+		}
+		te.RawDuration = raw
+		if err := te.Duration.UnmarshalJSON(aux.Duration); err != nil {
+			return err
+		}
+	}
+	if te.WorkspaceId == 0 {
+		te.WorkspaceId = aux.V9WorkspaceId
+	}
+	if te.ProjectId == 0 {
+		te.ProjectId = aux.V9ProjectId
+	}
+	return nil
+}
+
+// MarshalJSON encodes the entry so that UnmarshalJSON reads it back the same.
+// A running entry keeps its negative RawDuration rather than the elapsed
+// time, so it still decodes as running.
+func (te TimeEntry) MarshalJSON() ([]byte, error) {
+	type timeEntry TimeEntry
+	aux := struct {
+		timeEntry
+		Duration interface{}
+	}{timeEntry: timeEntry(te), Duration: te.Duration}
+	if te.RawDuration < 0 {
+		aux.Duration = te.RawDuration
+	}
+	return json.Marshal(aux)
+}
+
+// IsRunning reports whether the entry is the active timer. Toggl leaves Stop
+// unset on it and sends its duration as minus the start timestamp, so either
+// counts as running.
+func (te TimeEntry) IsRunning() bool {
+	return te.Stop.IsZero() || te.RawDuration < 0
+}
+
+// Elapsed returns how long the entry has been tracked: the time since Start
+// for a running entry, and the stored duration for a finished one.
+func (te TimeEntry) Elapsed() time.Duration {
+	if te.IsRunning() {
+		return time.Since(te.Start)
+	}
+	return te.Duration.Duration
+}
+
+// WasTimed guesses whether the entry was tracked live with a timer rather
+// than backfilled by hand. Timed entries have a real start time, and once
+// stopped their stop minus start matches the duration to the second.
+func (te TimeEntry) WasTimed() bool {
+	if te.DurOnly || te.Start.IsZero() {
+		return false
+	}
+	if te.Stop.IsZero() {
+		return true
+	}
+	return te.Stop.Sub(te.Start).Truncate(time.Second) == te.Duration.Duration
+}
+
+// CalendarEvent is a neutral representation of a time entry for calendar
+// exporters.
+type CalendarEvent struct {
+	Title       string
+	Start       time.Time
+	End         time.Time
+	Description string
+}
+
+// ToEvent converts the entry into a CalendarEvent. The entry description
+// becomes the title and the tags become the event description. A running
+// entry ends now.
+func (te TimeEntry) ToEvent() (CalendarEvent, error) {
+	if te.Start.IsZero() {
+		return CalendarEvent{}, fmt.Errorf("Couldn't convert time entry %v to event: no start time\n", te.Id)
+	}
+	end := te.Stop
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return CalendarEvent{
+		Title:       te.Description,
+		Start:       te.Start,
+		End:         end,
+		Description: strings.Join(te.Tags, ", "),
+	}, nil
 }
 
 // TimeEntryResponse is a wrapper for the data returned by /time_entries
@@ -67,34 +306,908 @@ type TimeEntriesResponse []TimeEntry
 // TimeEntriesService accesses /time_entries
 type TimeEntriesService struct {
 	client *Client
+
+	mu     sync.Mutex
+	paused *PausedEntry
 }
 
 // Get returns details of a single time entry
 func (tes *TimeEntriesService) Get(id int) (TimeEntry, error) {
-	panic("Get() Not yet implemented")
-	return TimeEntry{}, nil
+	return tes.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get with a context.
+func (tes *TimeEntriesService) GetContext(ctx context.Context, id int) (TimeEntry, error) {
+	resp := TimeEntryResponse{}
+	err := tes.client.GETContext(ctx, fmt.Sprintf("time_entries/%d", id), &resp)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't get time entry %v: %w\n", id, err)
+	}
+	if resp.Data.Id == 0 {
+		return TimeEntry{}, fmt.Errorf("Couldn't get time entry %v: %w\n", id, ErrNotFound)
+	}
+	return resp.Data, nil
+}
+
+// GetMany returns the time entries with the given ids in a single request.
+// Ids that are not found are left out, so fewer entries than ids may be
+// returned. No request is made for no ids.
+func (tes *TimeEntriesService) GetMany(ids []int) ([]TimeEntry, error) {
+	return tes.GetManyContext(context.Background(), ids)
+}
+
+// GetManyContext is like GetMany with a context.
+func (tes *TimeEntriesService) GetManyContext(ctx context.Context, ids []int) ([]TimeEntry, error) {
+	if len(ids) == 0 {
+		return []TimeEntry{}, nil
+	}
+	raw := json.RawMessage{}
+	err := tes.client.doData(ctx, "GET", "time_entries/"+joinIds(ids), nil, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get time entries %v: %w\n", ids, err)
+	}
+	entries, err := unmarshalEntries(raw)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get time entries %v: %w\n", ids, err)
+	}
+	return entries, nil
 }
 
-// Current returns running time entry
+// Current returns the running time entry. If nothing is running Toggl
+// answers {"data": null}, and an error wrapping ErrNotRunning is returned.
 func (tes *TimeEntriesService) Current() (TimeEntry, error) {
-	panic("Current() not yet implemented")
-	return TimeEntry{}, nil
+	return tes.CurrentContext(context.Background())
+}
+
+// CurrentContext is like Current with a context.
+func (tes *TimeEntriesService) CurrentContext(ctx context.Context) (TimeEntry, error) {
+	resp := struct{ Data *TimeEntry }{}
+	err := tes.client.GETContext(ctx, "time_entries/current", &resp)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't get current time entry: %w\n", err)
+	}
+	if resp.Data == nil || resp.Data.Id == 0 {
+		return TimeEntry{}, fmt.Errorf("Couldn't get current time entry: %w\n", ErrNotRunning)
+	}
+	return *resp.Data, nil
 }
 
+// RangeLimit is the most time entries Toggl returns for a single range query.
+const RangeLimit = 1000
+
 // Range returns time entries started in a specific time range. Only the first
-// 1000 found time entries are returned. There is no pagination.
+// RangeLimit found time entries are returned. There is no pagination, so if
+// the limit is hit the entries are returned together with an error wrapping
+// ErrRangeTruncated. Use RangeAll to get every entry.
 func (tes *TimeEntriesService) Range(start, end time.Time) ([]TimeEntry, error) {
+	return tes.RangeContext(context.Background(), start, end)
+}
+
+// RangeContext is like Range with a context.
+func (tes *TimeEntriesService) RangeContext(ctx context.Context, start, end time.Time) ([]TimeEntry, error) {
 	timeEntries := []TimeEntry{}
-	t0 := start.Format(time.RFC3339)
-	t1 := end.Format(time.RFC3339)
+	t0 := url.QueryEscape(start.Format(time.RFC3339))
+	t1 := url.QueryEscape(end.Format(time.RFC3339))
 	path := fmt.Sprintf("time_entries?start_date=%s&end_date=%s", t0, t1)
-	err := tes.client.GET(path, &timeEntries)
+	err := tes.client.doData(ctx, "GET", path, nil, &timeEntries)
 	if err != nil {
-		return nil, fmt.Errorf("Couldn't get time entries: %v\n", err)
+		return nil, fmt.Errorf("Couldn't get time entries: %w\n", err)
+	}
+	if len(timeEntries) >= RangeLimit {
+		return timeEntries, fmt.Errorf("Couldn't get all time entries: %w\n", ErrRangeTruncated)
 	}
 	return timeEntries, nil
 }
 
+// RangeAll returns every time entry started in a time range, sorted by start
+// time. Ranges that hit RangeLimit are split in half and fetched again until
+// each part fits, and entries that show up in more than one part are only
+// returned once.
+func (tes *TimeEntriesService) RangeAll(start, end time.Time) ([]TimeEntry, error) {
+	return tes.RangeAllContext(context.Background(), start, end)
+}
+
+// RangeAllContext is like RangeAll with a context.
+func (tes *TimeEntriesService) RangeAllContext(ctx context.Context, start, end time.Time) ([]TimeEntry, error) {
+	seen := map[int]bool{}
+	all := []TimeEntry{}
+	windows := [][2]time.Time{{start, end}}
+	for len(windows) > 0 {
+		w := windows[0]
+		windows = windows[1:]
+		entries, err := tes.RangeContext(ctx, w[0], w[1])
+		if errors.Is(err, ErrRangeTruncated) && w[1].Sub(w[0]) > time.Second {
+			mid := w[0].Add(w[1].Sub(w[0]) / 2)
+			windows = append(windows, [2]time.Time{w[0], mid}, [2]time.Time{mid, w[1]})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't get all time entries: %w\n", err)
+		}
+		for _, te := range entries {
+			if !seen[te.Id] {
+				seen[te.Id] = true
+				all = append(all, te)
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Start.Before(all[j].Start) })
+	return all, nil
+}
+
+// RangeConcurrent is like RangeAll, but splits the range into calendar
+// months, in the location of start, that are fetched in parallel by up to
+// workers goroutines. The first and last months are cut to the range.
+// Requests still go through the client's MaxConcurrency limit and are spaced
+// by its MinRequestInterval, so raising workers above MaxConcurrency does not
+// add load. With the default MinRequestInterval of zero, parallel requests
+// can trip Toggl's rate limit, and the 429s are retried with backoff; set it
+// to TogglRequestInterval to avoid them. If any month fails, the returned
+// error joins the errors of every failed month.
+func (tes *TimeEntriesService) RangeConcurrent(start, end time.Time, workers int) ([]TimeEntry, error) {
+	return tes.RangeConcurrentContext(context.Background(), start, end, workers)
+}
+
+// RangeConcurrentContext is like RangeConcurrent with a context.
+func (tes *TimeEntriesService) RangeConcurrentContext(ctx context.Context, start, end time.Time, workers int) ([]TimeEntry, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	windows := [][2]time.Time{}
+	for from := start; from.Before(end); {
+		to := time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, from.Location())
+		if to.After(end) {
+			to = end
+		}
+		windows = append(windows, [2]time.Time{from, to})
+		from = to
+	}
+	results := make([][]TimeEntry, len(windows))
+	errs := make([]error, len(windows))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for w := range next {
+				results[w], errs[w] = tes.RangeAllContext(ctx, windows[w][0], windows[w][1])
+			}
+		}()
+	}
+	for w := range windows {
+		next <- w
+	}
+	close(next)
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("Couldn't get time entries concurrently: %w\n", err)
+	}
+	seen := map[int]bool{}
+	all := []TimeEntry{}
+	for _, entries := range results {
+		for _, te := range entries {
+			if !seen[te.Id] {
+				seen[te.Id] = true
+				all = append(all, te)
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Start.Before(all[j].Start) })
+	return all, nil
+}
+
+// RangeInWorkspace is like RangeAll, but only returns the entries in the
+// given workspace. Toggl's time entry listing covers every workspace of the
+// user, so the other workspaces' entries are fetched and dropped.
+func (tes *TimeEntriesService) RangeInWorkspace(workspaceId int, start, end time.Time) ([]TimeEntry, error) {
+	return tes.RangeInWorkspaceContext(context.Background(), workspaceId, start, end)
+}
+
+// RangeInWorkspaceContext is like RangeInWorkspace with a context.
+func (tes *TimeEntriesService) RangeInWorkspaceContext(ctx context.Context, workspaceId int, start, end time.Time) ([]TimeEntry, error) {
+	entries, err := tes.RangeAllContext(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	inWorkspace := []TimeEntry{}
+	for _, te := range entries {
+		if te.WorkspaceId == workspaceId {
+			inWorkspace = append(inWorkspace, te)
+		}
+	}
+	return inWorkspace, nil
+}
+
+// TotalsByProject sums the time tracked per project id between start and
+// end, with entries without a project under id 0. Running entries are
+// skipped, since their duration is not final. Toggl only returns entries that
+// started in the range, so an entry that runs past end only counts the part
+// before end, prorated like SplitAcrossMidnight.
+func (tes *TimeEntriesService) TotalsByProject(start, end time.Time) (map[int]time.Duration, error) {
+	return tes.TotalsByProjectContext(context.Background(), start, end)
+}
+
+// TotalsByProjectContext is like TotalsByProject with a context.
+func (tes *TimeEntriesService) TotalsByProjectContext(ctx context.Context, start, end time.Time) (map[int]time.Duration, error) {
+	entries, err := tes.RangeAllContext(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get project totals: %w\n", err)
+	}
+	totals := map[int]time.Duration{}
+	for _, te := range entries {
+		if te.IsRunning() {
+			continue
+		}
+		d := te.Duration.Duration
+		if span := te.Stop.Sub(te.Start); te.Stop.After(end) && span > 0 {
+			d = time.Duration(float64(d) * float64(end.Sub(te.Start)) / float64(span))
+		}
+		totals[te.ProjectId] += d
+	}
+	return totals, nil
+}
+
+// RangeByTag is like RangeAll, but only returns the entries with at least one
+// of tags. Tags are matched ignoring case, as Toggl treats them.
+func (tes *TimeEntriesService) RangeByTag(start, end time.Time, tags []string) ([]TimeEntry, error) {
+	return tes.RangeByTagContext(context.Background(), start, end, tags)
+}
+
+// RangeByTagContext is like RangeByTag with a context.
+func (tes *TimeEntriesService) RangeByTagContext(ctx context.Context, start, end time.Time, tags []string) ([]TimeEntry, error) {
+	return tes.rangeByTags(ctx, start, end, tags, false)
+}
+
+// RangeByAllTags is like RangeByTag, but only returns the entries with every
+// one of tags.
+func (tes *TimeEntriesService) RangeByAllTags(start, end time.Time, tags []string) ([]TimeEntry, error) {
+	return tes.RangeByAllTagsContext(context.Background(), start, end, tags)
+}
+
+// RangeByAllTagsContext is like RangeByAllTags with a context.
+func (tes *TimeEntriesService) RangeByAllTagsContext(ctx context.Context, start, end time.Time, tags []string) ([]TimeEntry, error) {
+	return tes.rangeByTags(ctx, start, end, tags, true)
+}
+
+func (tes *TimeEntriesService) rangeByTags(ctx context.Context, start, end time.Time, tags []string, all bool) ([]TimeEntry, error) {
+	entries, err := tes.RangeAllContext(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	tagged := []TimeEntry{}
+	for _, te := range entries {
+		if hasTags(te, tags, all) {
+			tagged = append(tagged, te)
+		}
+	}
+	return tagged, nil
+}
+
+// Search is like RangeAll, but only returns the entries whose description
+// contains query, ignoring case. The filtering is done here rather than by
+// Toggl, since the main API has no description filter and the reports API's
+// needs a workspace.
+func (tes *TimeEntriesService) Search(start, end time.Time, query string) ([]TimeEntry, error) {
+	return tes.SearchContext(context.Background(), start, end, query)
+}
+
+// SearchContext is like Search with a context.
+func (tes *TimeEntriesService) SearchContext(ctx context.Context, start, end time.Time, query string) ([]TimeEntry, error) {
+	entries, err := tes.RangeAllContext(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	query = strings.ToLower(query)
+	found := []TimeEntry{}
+	for _, te := range entries {
+		if strings.Contains(strings.ToLower(te.Description), query) {
+			found = append(found, te)
+		}
+	}
+	return found, nil
+}
+
+// hasTags reports whether te has any, or with all set every, one of tags,
+// ignoring case.
+func hasTags(te TimeEntry, tags []string, all bool) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range te.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if found != all {
+			return found
+		}
+	}
+	return all
+}
+
+// newTimeEntry is the body sent to create a time entry. Unset fields are
+// left out so that Toggl applies its defaults.
+type newTimeEntry struct {
+	Description string   `json:"description,omitempty"`
+	WorkspaceId int      `json:"wid,omitempty"`
+	ProjectId   int      `json:"pid,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Billable    bool     `json:"billable,omitempty"`
+	CreatedWith string   `json:"created_with"`
+}
+
+// Start starts a new running time entry and returns it with its Id and Start
+// filled in. Only Description, WorkspaceId, ProjectId, Tags, Billable and
+// CreatedWith are sent, and unset fields are left to Toggl's defaults, except
+// CreatedWith, which Toggl requires and defaults to the client's UserAgent.
+func (tes *TimeEntriesService) Start(entry TimeEntry) (TimeEntry, error) {
+	return tes.StartContext(context.Background(), entry)
+}
+
+// StartContext is like Start with a context.
+func (tes *TimeEntriesService) StartContext(ctx context.Context, entry TimeEntry) (TimeEntry, error) {
+	// Toggl rejects entries without created_with.
+	createdWith := entry.CreatedWith
+	if createdWith == "" {
+		createdWith = tes.client.UserAgent
+	}
+	if createdWith == "" {
+		createdWith = UserAgent
+	}
+	body := map[string]newTimeEntry{"time_entry": {
+		Description: entry.Description,
+		WorkspaceId: entry.WorkspaceId,
+		ProjectId:   entry.ProjectId,
+		Tags:        entry.Tags,
+		Billable:    entry.Billable,
+		CreatedWith: createdWith,
+	}}
+	started := TimeEntry{}
+	err := tes.client.doData(ctx, "POST", "time_entries/start", body, &started)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't start time entry: %w\n", err)
+	}
+	return started, nil
+}
+
+// Stop stops a running time entry and returns it with Stop and the final
+// Duration filled in. Toggl answers a stop of a finished entry by returning
+// it unchanged, so the entry is fetched first and ErrAlreadyStopped returned
+// if it is not running.
+func (tes *TimeEntriesService) Stop(id int) (TimeEntry, error) {
+	return tes.StopContext(context.Background(), id)
+}
+
+// StopContext is like Stop with a context.
+func (tes *TimeEntriesService) StopContext(ctx context.Context, id int) (TimeEntry, error) {
+	current, err := tes.GetContext(ctx, id)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't stop time entry %v: %w\n", id, err)
+	}
+	if !current.IsRunning() {
+		return TimeEntry{}, fmt.Errorf("Couldn't stop time entry %v: %w\n", id, ErrAlreadyStopped)
+	}
+	stopped := TimeEntry{}
+	err = tes.client.doData(ctx, "PUT", fmt.Sprintf("time_entries/%d/stop", id), nil, &stopped)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't stop time entry %v: %w\n", id, err)
+	}
+	return stopped, nil
+}
+
+// StopCurrent stops the running time entry, whatever its id, and returns it
+// like Stop. If nothing is running an error wrapping ErrNotRunning is
+// returned.
+func (tes *TimeEntriesService) StopCurrent() (TimeEntry, error) {
+	return tes.StopCurrentContext(context.Background())
+}
+
+// StopCurrentContext is like StopCurrent with a context.
+func (tes *TimeEntriesService) StopCurrentContext(ctx context.Context) (TimeEntry, error) {
+	current, err := tes.CurrentContext(ctx)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't stop current time entry: %w\n", err)
+	}
+	stopped, err := tes.StopContext(ctx, current.Id)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't stop current time entry: %w\n", err)
+	}
+	return stopped, nil
+}
+
+// timeEntryUpdate is the body sent to update a time entry. Every field is
+// sent, so that clearing one in the TimeEntry clears it in Toggl too.
+type timeEntryUpdate struct {
+	Description string     `json:"description"`
+	ProjectId   *int       `json:"pid"`
+	Tags        []string   `json:"tags"`
+	Billable    bool       `json:"billable"`
+	Start       time.Time  `json:"start"`
+	Stop        *time.Time `json:"stop,omitempty"`
+	Duration    int64      `json:"duration"`
+}
+
+// Update replaces the description, project, tags, billable flag, start,
+// stop and duration of the time entry with entry.Id, and returns the entry
+// as Toggl stored it. A ProjectId of 0 removes the project. A running entry
+// stays running, as long as its RawDuration is kept as Toggl sent it.
+func (tes *TimeEntriesService) Update(entry TimeEntry) (TimeEntry, error) {
+	return tes.UpdateContext(context.Background(), entry)
+}
+
+// UpdateContext is like Update with a context.
+func (tes *TimeEntriesService) UpdateContext(ctx context.Context, entry TimeEntry) (TimeEntry, error) {
+	update := timeEntryUpdate{
+		Description: entry.Description,
+		Tags:        entry.Tags,
+		Billable:    entry.Billable,
+		Start:       entry.Start,
+		Duration:    int64(entry.Duration.Seconds()),
+	}
+	if entry.ProjectId != 0 {
+		update.ProjectId = &entry.ProjectId
+	}
+	if update.Tags == nil {
+		update.Tags = []string{}
+	}
+	if entry.RawDuration < 0 {
+		update.Duration = entry.RawDuration
+	} else if !entry.Stop.IsZero() {
+		update.Stop = &entry.Stop
+	}
+	updated := TimeEntry{}
+	body := map[string]timeEntryUpdate{"time_entry": update}
+	err := tes.client.doData(ctx, "PUT", fmt.Sprintf("time_entries/%d", entry.Id), body, &updated)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't update time entry %v: %w\n", entry.Id, err)
+	}
+	return updated, nil
+}
+
+// UpdateIfUnmodified is like Update, but first fetches the entry again and
+// returns an error wrapping ErrConflict if its At differs from entry.At, so
+// edits made elsewhere, such as in the Toggl web app, since entry was read
+// are not overwritten. entry must come from Toggl with At set. Toggl has no
+// conditional update, so an edit landing between the check and the update
+// still goes unnoticed.
+func (tes *TimeEntriesService) UpdateIfUnmodified(entry TimeEntry) (TimeEntry, error) {
+	return tes.UpdateIfUnmodifiedContext(context.Background(), entry)
+}
+
+// UpdateIfUnmodifiedContext is like UpdateIfUnmodified with a context.
+func (tes *TimeEntriesService) UpdateIfUnmodifiedContext(ctx context.Context, entry TimeEntry) (TimeEntry, error) {
+	if entry.At.IsZero() {
+		return TimeEntry{}, fmt.Errorf("Couldn't update time entry %v: At is not set\n", entry.Id)
+	}
+	current, err := tes.GetContext(ctx, entry.Id)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't update time entry %v: %w\n", entry.Id, err)
+	}
+	if !current.At.Equal(entry.At) {
+		return TimeEntry{}, fmt.Errorf("Couldn't update time entry %v, read at %v and modified at %v: %w\n",
+			entry.Id, entry.At.Format(time.RFC3339), current.At.Format(time.RFC3339), ErrConflict)
+	}
+	return tes.UpdateContext(ctx, entry)
+}
+
+// Delete deletes a time entry. Deleting an entry that does not exist returns
+// an error wrapping ErrNotFound.
+func (tes *TimeEntriesService) Delete(id int) error {
+	return tes.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete with a context.
+func (tes *TimeEntriesService) DeleteContext(ctx context.Context, id int) error {
+	err := tes.client.DELETEContext(ctx, fmt.Sprintf("time_entries/%d", id))
+	if err != nil {
+		return fmt.Errorf("Couldn't delete time entry %v: %w\n", id, err)
+	}
+	return nil
+}
+
+// UpdateTags adds tags to, or removes them from, several time entries in a
+// single request. action is "add" or "remove". The updated entries are
+// returned. Entries Toggl did not update, such as deleted ones, are silently
+// missing from the result; use UpdateTagsDetailed to find out which.
+func (tes *TimeEntriesService) UpdateTags(ids []int, tags []string, action string) ([]TimeEntry, error) {
+	return tes.UpdateTagsContext(context.Background(), ids, tags, action)
+}
+
+// UpdateTagsContext is like UpdateTags with a context.
+func (tes *TimeEntriesService) UpdateTagsContext(ctx context.Context, ids []int, tags []string, action string) ([]TimeEntry, error) {
+	if err := checkTagUpdate(ids, action); err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{"time_entry": map[string]interface{}{
+		"tags":       tags,
+		"tag_action": action,
+	}}
+	raw := json.RawMessage{}
+	err := tes.client.doData(ctx, "PUT", "time_entries/"+joinIds(ids), body, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't update tags: %w\n", err)
+	}
+	entries, err := unmarshalEntries(raw)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't update tags: %w\n", err)
+	}
+	return entries, nil
+}
+
+// checkTagUpdate returns an error for arguments UpdateTags should not send.
+func checkTagUpdate(ids []int, action string) error {
+	if action != "add" && action != "remove" {
+		return fmt.Errorf("Couldn't update tags: action must be \"add\" or \"remove\", not %q\n", action)
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("Couldn't update tags: no time entry ids\n")
+	}
+	return nil
+}
+
+// TagUpdateResult is the outcome of UpdateTagsDetailed for each entry.
+// Updated holds the entries Toggl returned, and Failed the reason for every
+// requested id that was not updated.
+type TagUpdateResult struct {
+	Updated []TimeEntry
+	Failed  map[int]error
+}
+
+// UpdateTagsDetailed is like UpdateTags, but reports the outcome for every
+// id. Ids Toggl leaves out of its answer fail with an error wrapping
+// ErrNotFound. If Toggl rejects the whole request, for example because one
+// entry is in a workspace the user can't access, each id is retried on its
+// own, which is safe since adding or removing a tag twice changes nothing.
+// The error is only set for invalid arguments or a cancelled context.
+func (tes *TimeEntriesService) UpdateTagsDetailed(ids []int, tags []string, action string) (TagUpdateResult, error) {
+	return tes.UpdateTagsDetailedContext(context.Background(), ids, tags, action)
+}
+
+// UpdateTagsDetailedContext is like UpdateTagsDetailed with a context.
+func (tes *TimeEntriesService) UpdateTagsDetailedContext(ctx context.Context, ids []int, tags []string, action string) (TagUpdateResult, error) {
+	if err := checkTagUpdate(ids, action); err != nil {
+		return TagUpdateResult{}, err
+	}
+	result := TagUpdateResult{Updated: []TimeEntry{}, Failed: map[int]error{}}
+	entries, err := tes.UpdateTagsContext(ctx, ids, tags, action)
+	if err == nil {
+		result.add(ids, entries)
+		return result, nil
+	}
+	if ctx.Err() != nil {
+		return TagUpdateResult{}, err
+	}
+	if len(ids) == 1 {
+		result.Failed[ids[0]] = err
+		return result, nil
+	}
+	for _, id := range ids {
+		entries, err := tes.UpdateTagsContext(ctx, []int{id}, tags, action)
+		if ctx.Err() != nil {
+			return TagUpdateResult{}, err
+		}
+		if err != nil {
+			result.Failed[id] = err
+			continue
+		}
+		result.add([]int{id}, entries)
+	}
+	return result, nil
+}
+
+// add records the entries Toggl returned for ids, and marks the ids that
+// are missing from them as failed.
+func (r *TagUpdateResult) add(ids []int, entries []TimeEntry) {
+	returned := map[int]bool{}
+	for _, te := range entries {
+		returned[te.Id] = true
+	}
+	r.Updated = append(r.Updated, entries...)
+	for _, id := range ids {
+		if !returned[id] {
+			r.Failed[id] = fmt.Errorf("Time entry %v was not updated: %w\n", id, ErrNotFound)
+		}
+	}
+}
+
+// AddTags adds tags to a single time entry without replacing its other
+// tags, so there is no need to fetch the entry first.
+func (tes *TimeEntriesService) AddTags(id int, tags []string) (TimeEntry, error) {
+	return tes.AddTagsContext(context.Background(), id, tags)
+}
+
+// AddTagsContext is like AddTags with a context.
+func (tes *TimeEntriesService) AddTagsContext(ctx context.Context, id int, tags []string) (TimeEntry, error) {
+	return tes.updateTags(ctx, id, tags, "add")
+}
+
+// RemoveTags removes tags from a single time entry, keeping its other tags.
+func (tes *TimeEntriesService) RemoveTags(id int, tags []string) (TimeEntry, error) {
+	return tes.RemoveTagsContext(context.Background(), id, tags)
+}
+
+// RemoveTagsContext is like RemoveTags with a context.
+func (tes *TimeEntriesService) RemoveTagsContext(ctx context.Context, id int, tags []string) (TimeEntry, error) {
+	return tes.updateTags(ctx, id, tags, "remove")
+}
+
+func (tes *TimeEntriesService) updateTags(ctx context.Context, id int, tags []string, action string) (TimeEntry, error) {
+	entries, err := tes.UpdateTagsContext(ctx, []int{id}, tags, action)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+	if len(entries) == 0 {
+		return TimeEntry{}, fmt.Errorf("Couldn't update tags of time entry %v: %w\n", id, ErrNotFound)
+	}
+	return entries[0], nil
+}
+
+// joinIds joins ids with commas, as Toggl takes several ids in a path.
+func joinIds(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+// unmarshalEntries decodes either a single time entry or an array of them.
+// Toggl answers a request for several ids with an array, unless there was
+// only one id. null, and entries without an id, are left out.
+func unmarshalEntries(raw json.RawMessage) ([]TimeEntry, error) {
+	decoded := []TimeEntry{}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &decoded); err != nil {
+			return nil, err
+		}
+	} else {
+		te := TimeEntry{}
+		if err := json.Unmarshal(raw, &te); err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, te)
+	}
+	entries := []TimeEntry{}
+	for _, te := range decoded {
+		if te.Id != 0 {
+			entries = append(entries, te)
+		}
+	}
+	return entries, nil
+}
+
+// StartWithTags starts a new running time entry with the given tags already
+// set, in a single request.
+func (tes *TimeEntriesService) StartWithTags(desc string, wid, pid int, tags []string) (TimeEntry, error) {
+	return tes.StartWithTagsContext(context.Background(), desc, wid, pid, tags)
+}
+
+// StartWithTagsContext is like StartWithTags with a context.
+func (tes *TimeEntriesService) StartWithTagsContext(ctx context.Context, desc string, wid, pid int, tags []string) (TimeEntry, error) {
+	return tes.StartContext(ctx, TimeEntry{
+		Description: desc,
+		WorkspaceId: wid,
+		ProjectId:   pid,
+		Tags:        tags,
+	})
+}
+
+// StartIfNotRunning starts entry unless a time entry is already running, in
+// which case the running entry is returned with started false. This keeps
+// a repeated start from leaving two timers running.
+func (tes *TimeEntriesService) StartIfNotRunning(entry TimeEntry) (TimeEntry, bool, error) {
+	return tes.StartIfNotRunningContext(context.Background(), entry)
+}
+
+// StartIfNotRunningContext is like StartIfNotRunning with a context.
+func (tes *TimeEntriesService) StartIfNotRunningContext(ctx context.Context, entry TimeEntry) (TimeEntry, bool, error) {
+	current, err := tes.CurrentContext(ctx)
+	if err == nil {
+		return current, false, nil
+	}
+	if !errors.Is(err, ErrNotRunning) {
+		return TimeEntry{}, false, fmt.Errorf("Couldn't start time entry: %w\n", err)
+	}
+	started, err := tes.StartContext(ctx, entry)
+	if err != nil {
+		return TimeEntry{}, false, err
+	}
+	return started, true, nil
+}
+
+// StartByProjectName starts a new running time entry in the project named name
+// in workspace wid, looked up with ProjectsService.ByName.
+func (tes *TimeEntriesService) StartByProjectName(name string, wid int, desc string) (TimeEntry, error) {
+	return tes.StartByProjectNameContext(context.Background(), name, wid, desc)
+}
+
+// StartByProjectNameContext is like StartByProjectName with a context.
+func (tes *TimeEntriesService) StartByProjectNameContext(ctx context.Context, name string, wid int, desc string) (TimeEntry, error) {
+	project, err := tes.client.Projects.ByNameContext(ctx, wid, name)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't start time entry: %w\n", err)
+	}
+	return tes.StartContext(ctx, TimeEntry{
+		Description: desc,
+		WorkspaceId: wid,
+		ProjectId:   project.Id,
+	})
+}
+
+// SwitchTo stops the running time entry, if any, and starts entry. stopped
+// is the entry that was stopped, or nil if nothing was running. If stopping
+// fails nothing is started.
+func (tes *TimeEntriesService) SwitchTo(entry TimeEntry) (stopped *TimeEntry, started TimeEntry, err error) {
+	return tes.SwitchToContext(context.Background(), entry)
+}
+
+// SwitchToContext is like SwitchTo with a context.
+func (tes *TimeEntriesService) SwitchToContext(ctx context.Context, entry TimeEntry) (stopped *TimeEntry, started TimeEntry, err error) {
+	current, err := tes.CurrentContext(ctx)
+	switch {
+	case errors.Is(err, ErrNotRunning):
+	case err != nil:
+		return nil, TimeEntry{}, fmt.Errorf("Couldn't switch time entry: %w\n", err)
+	default:
+		// The entry may have been stopped elsewhere since Current.
+		te, err := tes.StopContext(ctx, current.Id)
+		if err != nil && !errors.Is(err, ErrAlreadyStopped) {
+			return nil, TimeEntry{}, fmt.Errorf("Couldn't switch time entry: %w\n", err)
+		}
+		if err == nil {
+			stopped = &te
+		}
+	}
+	started, err = tes.StartContext(ctx, entry)
+	if err != nil {
+		return stopped, TimeEntry{}, fmt.Errorf("Couldn't switch time entry: %w\n", err)
+	}
+	return stopped, started, nil
+}
+
+// PausedEntry is what Pause remembers about the stopped entry, so that
+// Resume can start it again.
+type PausedEntry struct {
+	Description string
+	WorkspaceId int
+	ProjectId   int
+	Tags        []string
+	Billable    bool
+	PausedAt    time.Time
+}
+
+// ErrNotPaused is returned by TimeEntriesService.Resume when there is no
+// paused entry to resume.
+var ErrNotPaused = errors.New("No time entry is paused")
+
+// Pause stops the running time entry and remembers it for Resume. If nothing
+// is running an error wrapping ErrNotRunning is returned and the previously
+// paused entry, if any, is kept.
+func (tes *TimeEntriesService) Pause() (stopped TimeEntry, err error) {
+	return tes.PauseContext(context.Background())
+}
+
+// PauseContext is like Pause with a context.
+func (tes *TimeEntriesService) PauseContext(ctx context.Context) (stopped TimeEntry, err error) {
+	tes.mu.Lock()
+	defer tes.mu.Unlock()
+	current, err := tes.CurrentContext(ctx)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't pause: %w\n", err)
+	}
+	stopped, err = tes.StopContext(ctx, current.Id)
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't pause: %w\n", err)
+	}
+	tes.paused = &PausedEntry{
+		Description: current.Description,
+		WorkspaceId: current.WorkspaceId,
+		ProjectId:   current.ProjectId,
+		Tags:        current.Tags,
+		Billable:    current.Billable,
+		PausedAt:    stopped.Stop,
+	}
+	return stopped, nil
+}
+
+// Paused returns the entry the last Pause stopped, if it has not been
+// resumed yet.
+func (tes *TimeEntriesService) Paused() (PausedEntry, bool) {
+	tes.mu.Lock()
+	defer tes.mu.Unlock()
+	if tes.paused == nil {
+		return PausedEntry{}, false
+	}
+	return *tes.paused, true
+}
+
+// Resume starts a new time entry like the one the last Pause stopped. It
+// returns an error wrapping ErrNotPaused if nothing was paused.
+func (tes *TimeEntriesService) Resume() (TimeEntry, error) {
+	return tes.ResumeContext(context.Background())
+}
+
+// ResumeContext is like Resume with a context.
+func (tes *TimeEntriesService) ResumeContext(ctx context.Context) (TimeEntry, error) {
+	tes.mu.Lock()
+	defer tes.mu.Unlock()
+	if tes.paused == nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't resume: %w\n", ErrNotPaused)
+	}
+	started, err := tes.StartContext(ctx, TimeEntry{
+		Description: tes.paused.Description,
+		WorkspaceId: tes.paused.WorkspaceId,
+		ProjectId:   tes.paused.ProjectId,
+		Tags:        tes.paused.Tags,
+		Billable:    tes.paused.Billable,
+	})
+	if err != nil {
+		return TimeEntry{}, fmt.Errorf("Couldn't resume: %w\n", err)
+	}
+	tes.paused = nil
+	return started, nil
+}
+
+// ModifiedLookback is how long before the window ModifiedBetween starts
+// looking for entries, since an entry can be edited long after it started.
+const ModifiedLookback = 90 * 24 * time.Hour
+
+// ModifiedBetween returns the entries last modified between start and end,
+// sorted by modification time. Toggl can only filter on start time, so this
+// fetches all entries started from ModifiedLookback before start until end,
+// and filters those on At.
+func (tes *TimeEntriesService) ModifiedBetween(start, end time.Time) ([]TimeEntry, error) {
+	return tes.ModifiedBetweenContext(context.Background(), start, end)
+}
+
+// ModifiedBetweenContext is like ModifiedBetween with a context.
+func (tes *TimeEntriesService) ModifiedBetweenContext(ctx context.Context, start, end time.Time) ([]TimeEntry, error) {
+	entries, err := tes.RangeAllContext(ctx, start.Add(-ModifiedLookback), end)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get modified time entries: %w\n", err)
+	}
+	modified := []TimeEntry{}
+	for _, te := range entries {
+		if te.At.IsZero() || te.At.Before(start) || te.At.After(end) {
+			continue
+		}
+		modified = append(modified, te)
+	}
+	sort.SliceStable(modified, func(i, j int) bool {
+		return modified[i].At.Before(modified[j].At)
+	})
+	return modified, nil
+}
+
+// ChangedSince returns the entries created or modified after since, sorted
+// by modification time, for incremental syncing. Toggl is asked for only the
+// related data changed since then, in whole seconds, and the entries are
+// then filtered on At. Deleted entries are not returned.
+func (tes *TimeEntriesService) ChangedSince(since time.Time) ([]TimeEntry, error) {
+	return tes.ChangedSinceContext(context.Background(), since)
+}
+
+// ChangedSinceContext is like ChangedSince with a context.
+func (tes *TimeEntriesService) ChangedSinceContext(ctx context.Context, since time.Time) ([]TimeEntry, error) {
+	related := struct {
+		TimeEntries []TimeEntry `json:"time_entries"`
+	}{}
+	path := fmt.Sprintf("me?with_related_data=true&since=%d", since.Unix())
+	err := tes.client.getData(ctx, path, &related)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get changed time entries: %w\n", err)
+	}
+	changed := []TimeEntry{}
+	for _, te := range related.TimeEntries {
+		if te.At.After(since) {
+			changed = append(changed, te)
+		}
+	}
+	sort.SliceStable(changed, func(i, j int) bool { return changed[i].At.Before(changed[j].At) })
+	return changed, nil
+}
+
 type User struct {
 	ApiToken              string `json:"api_token"`
 	DefaultWorkspaceId    int    `json:"default_wid"`
@@ -120,6 +1233,25 @@ type User struct {
 	SendTimerNotifications bool `json:"send_timer_notifications"`
 	OpenIdEnabled          bool `json:"openid_enabled"`
 	Timezone               string
+	RecordTimeline         bool `json:"record_timeline"`
+	RenderTimeline         bool `json:"render_timeline"`
+	TimelineEnabled        bool `json:"timeline_enabled"`
+}
+
+// UserPreferences holds the notification settings of a user.
+type UserPreferences struct {
+	SendProductEmails      bool `json:"send_product_emails"`
+	SendWeeklyReport       bool `json:"send_weekly_report"`
+	SendTimerNotifications bool `json:"send_timer_notifications"`
+}
+
+// Preferences returns the user's notification settings.
+func (u User) Preferences() UserPreferences {
+	return UserPreferences{
+		SendProductEmails:      u.SendProductEmails,
+		SendWeeklyReport:       u.SendWeeklyReport,
+		SendTimerNotifications: u.SendTimerNotifications,
+	}
 }
 
 type UserResponse struct {
@@ -131,62 +1263,512 @@ type MeService struct {
 	client *Client
 }
 
-// Get returns details of current user
+// Get returns details of current user. On a client made with
+// NewClientWithCredentials it also switches the client to the user's API
+// token.
 func (ms *MeService) Get() (User, error) {
-	userResp := UserResponse{}
-	err := ms.client.GET("me", &userResp)
+	return ms.GetContext(context.Background())
+}
+
+// GetContext is like Get with a context.
+func (ms *MeService) GetContext(ctx context.Context) (User, error) {
+	user := User{}
+	err := ms.client.doData(ctx, "GET", "me", nil, &user)
 	if err != nil {
-		return User{}, fmt.Errorf("Couldn't get time entries: %v\n", err)
+		return User{}, fmt.Errorf("Couldn't get time entries: %w\n", err)
+	}
+	if ms.client.ApiKey == "" && user.ApiToken != "" {
+		ms.client.ApiKey = user.ApiToken
+		ms.client.email, ms.client.password = "", ""
 	}
-	return userResp.Data, nil
+	return user, nil
+}
+
+// UpdatePreferences saves the current user's notification settings and
+// returns the updated user.
+func (ms *MeService) UpdatePreferences(prefs UserPreferences) (User, error) {
+	return ms.UpdatePreferencesContext(context.Background(), prefs)
+}
+
+// UpdatePreferencesContext is like UpdatePreferences with a context.
+func (ms *MeService) UpdatePreferencesContext(ctx context.Context, prefs UserPreferences) (User, error) {
+	user := User{}
+	err := ms.client.doData(ctx, "PUT", "me", map[string]UserPreferences{"user": prefs}, &user)
+	if err != nil {
+		return User{}, fmt.Errorf("Couldn't update preferences: %w\n", err)
+	}
+	return user, nil
+}
+
+// ResetAPIToken makes Toggl issue a new API token for the current user and
+// returns it. The old token stops working, so the client's ApiKey is switched
+// to the new one.
+func (ms *MeService) ResetAPIToken() (string, error) {
+	return ms.ResetAPITokenContext(context.Background())
+}
+
+// ResetAPITokenContext is like ResetAPIToken with a context.
+func (ms *MeService) ResetAPITokenContext(ctx context.Context) (string, error) {
+	token := ""
+	err := ms.client.POSTContext(ctx, "reset_token", nil, &token)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't reset API token: %w\n", err)
+	}
+	ms.client.ApiKey = token
+	return token, nil
 }
 
 // Client accesses the Toggl API using a given API key.
 type Client struct {
 	client      *http.Client
 	ApiKey      string
+	email       string // Used instead of ApiKey until it is known
+	password    string
+	BaseURL     string // Main API URL with a trailing slash, TogglApi by default
+	ReportsURL  string // Reports API URL with a trailing slash, ReportsApi by default
+	UserAgent   string // Sent as User-Agent on every request, UserAgent by default
 	TimeEntries *TimeEntriesService
 	Me          *MeService
+	Projects    *ProjectsService
+	Workspaces  *WorkspacesService
+	Reports     *ReportsService
+	Tags        *TagsService
+	Clients     *ClientsService
+	Tasks       *TasksService
+
+	// CachedWorkspaces and DefaultWorkspaceId are only set by LoadWorkspaces.
+	CachedWorkspaces   []Workspace
+	DefaultWorkspaceId int
+
+	// MaxConcurrency caps how many requests the client has in flight at
+	// once, including those fanned out by helpers. It must be set before
+	// the first request.
+	MaxConcurrency int
+	semOnce        sync.Once
+	sem            chan struct{}
+
+	// MaxRetries is how many times a request is retried after a 429, or
+	// after a 5xx unless it is a POST. Zero disables retries.
+	MaxRetries int
+
+	// MinRequestInterval is the least time between the starts of two
+	// requests, retries and requests fanned out by helpers included. Zero,
+	// the default, sends requests as soon as a MaxConcurrency slot is free.
+	MinRequestInterval time.Duration
+	rateMu             sync.Mutex
+	nextRequest        time.Time
+
+	// RequestHook, if set, is called with every request right before it is
+	// sent, retries included, so tests can check the method, URL, headers
+	// and body. Read the body through req.GetBody so it is still sent.
+	RequestHook func(req *http.Request)
+
+	// Logger, if set, gets a line with the method, URL, status and latency
+	// of every request, retries included, and warnings about misuse.
+	Logger Logger
+
+	lastMu   sync.Mutex
+	lastResp ResponseInfo
+}
+
+// Logger is what Client.Logger logs to. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// logf logs to the client's Logger, if it has one.
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, v...)
+	}
+}
+
+// ResponseInfo is what the client keeps of the last response it received.
+type ResponseInfo struct {
+	StatusCode int
+	Header     http.Header
+	Received   time.Time
+}
+
+// QuotaRemaining returns how many requests the X-Toggl-Quota-Remaining
+// header says are left before Toggl starts answering 429, and whether the
+// header was present.
+func (ri ResponseInfo) QuotaRemaining() (int, bool) {
+	n, err := strconv.Atoi(ri.Header.Get("X-Toggl-Quota-Remaining"))
+	return n, err == nil
+}
+
+// QuotaResetsIn returns how long the X-Toggl-Quota-Resets-In header says it
+// is until the quota is reset, and whether the header was present.
+func (ri ResponseInfo) QuotaResetsIn() (time.Duration, bool) {
+	seconds, err := strconv.Atoi(ri.Header.Get("X-Toggl-Quota-Resets-In"))
+	return time.Duration(seconds) * time.Second, err == nil
 }
 
-// NewClient creates a new Toggl API client using an API key.
+// LastResponse returns the status and headers of the last response the
+// client received, retries included. With concurrent requests it is
+// whichever finished last. It is the zero ResponseInfo before the first
+// response.
+func (c *Client) LastResponse() ResponseInfo {
+	c.lastMu.Lock()
+	defer c.lastMu.Unlock()
+	return c.lastResp
+}
+
+// NewClient creates a new Toggl API client using an API key. Requests time
+// out after DefaultTimeout. Whitespace around the key, as often comes with
+// copying it from the Toggl profile page, is trimmed. NewClient panics if the
+// key is empty, since every request would fail with 403.
 func NewClient(apiKey string) *Client {
+	return NewClientWithOptions(apiKey)
+}
+
+// NewClientWithHTTPClient creates a new Toggl API client using an API key
+// that sends its requests with hc, for a custom timeout, transport or proxy.
+// A nil hc gets the same client as NewClient. The key is trimmed, and an
+// empty key panics, like with NewClient.
+func NewClientWithHTTPClient(apiKey string, hc *http.Client) *Client {
+	return NewClientWithOptions(apiKey, WithHTTPClient(hc))
+}
+
+// NewClientWithCredentials creates a new Toggl API client that logs in with
+// an email and password instead of an API key, which Toggl allows with basic
+// auth too. The first successful Me.Get switches the client over to the API
+// token it returns, found in User.ApiToken, and forgets the password.
+func NewClientWithCredentials(email, password string) *Client {
+	c := newClient("", &http.Client{Timeout: DefaultTimeout})
+	c.email = email
+	c.password = password
+	return c
+}
+
+// newClient creates a client without checking apiKey.
+func newClient(apiKey string, hc *http.Client) *Client {
+	if hc == nil {
+		hc = &http.Client{Timeout: DefaultTimeout}
+	}
 	c := &Client{
-		client: &http.Client{},
-		ApiKey: apiKey,
+		client:         hc,
+		ApiKey:         apiKey,
+		BaseURL:        TogglApi,
+		ReportsURL:     ReportsApi,
+		UserAgent:      UserAgent,
+		MaxConcurrency: DefaultMaxConcurrency,
+		MaxRetries:     DefaultMaxRetries,
 	}
 	c.TimeEntries = &TimeEntriesService{client: c}
 	c.Me = &MeService{client: c}
+	c.Projects = &ProjectsService{client: c}
+	c.Workspaces = &WorkspacesService{client: c}
+	c.Reports = &ReportsService{client: c}
+	c.Tags = &TagsService{client: c}
+	c.Clients = &ClientsService{client: c}
+	c.Tasks = &TasksService{client: c}
 	return c
 }
 
+// APITokenEnv is the environment variable NewClientFromEnv reads.
+const APITokenEnv = "TOGGL_API_TOKEN"
+
+// NewClientFromEnv creates a new Toggl API client using the API key in the
+// TOGGL_API_TOKEN environment variable. Unlike NewClient it returns an error
+// rather than panicking if the variable is unset or empty.
+func NewClientFromEnv() (*Client, error) {
+	apiKey := strings.TrimSpace(os.Getenv(APITokenEnv))
+	if apiKey == "" {
+		return nil, fmt.Errorf("Couldn't create client: %v is not set\n", APITokenEnv)
+	}
+	return NewClient(apiKey), nil
+}
+
 // GET does a GET operation to the main API (not the reports API) and
 // unmarshals the result into the given interface.
 func (c *Client) GET(path string, response interface{}) error {
+	return c.GETContext(context.Background(), path, response)
+}
+
+// GETContext is like GET with a context.
+func (c *Client) GETContext(ctx context.Context, path string, response interface{}) error {
+	return c.do(ctx, "GET", c.BaseURL, path, nil, response)
+}
+
+// GetRaw does a GET operation to the main API and returns the response body
+// as is, for endpoints this package does not model. Errors are the same as
+// for GET.
+func (c *Client) GetRaw(path string) ([]byte, error) {
+	return c.GetRawContext(context.Background(), path)
+}
+
+// GetRawContext is like GetRaw with a context.
+func (c *Client) GetRawContext(ctx context.Context, path string) ([]byte, error) {
+	return c.doRaw(ctx, "GET", c.BaseURL, path, nil)
+}
+
+// DELETE does a DELETE operation to the main API. The response body, which
+// is empty on success, is ignored.
+func (c *Client) DELETE(path string) error {
+	return c.DELETEContext(context.Background(), path)
+}
+
+// DELETEContext is like DELETE with a context.
+func (c *Client) DELETEContext(ctx context.Context, path string) error {
+	return c.do(ctx, "DELETE", c.BaseURL, path, nil, nil)
+}
+
+// POST does a POST operation to the main API with body encoded as JSON, and
+// unmarshals the result into the given interface.
+func (c *Client) POST(path string, body interface{}, response interface{}) error {
+	return c.POSTContext(context.Background(), path, body, response)
+}
+
+// POSTContext is like POST with a context.
+func (c *Client) POSTContext(ctx context.Context, path string, body interface{}, response interface{}) error {
+	return c.do(ctx, "POST", c.BaseURL, path, body, response)
+}
+
+// PUT does a PUT operation to the main API with body encoded as JSON, and
+// unmarshals the result into the given interface.
+func (c *Client) PUT(path string, body interface{}, response interface{}) error {
+	return c.PUTContext(context.Background(), path, body, response)
+}
+
+// PUTContext is like PUT with a context.
+func (c *Client) PUTContext(ctx context.Context, path string, body interface{}, response interface{}) error {
+	return c.do(ctx, "PUT", c.BaseURL, path, body, response)
+}
+
+// do sends a request to baseURL+path and unmarshals the result into
+// response. A nil body sends no request body, and a nil response skips
+// decoding. An empty body, which some endpoints send on success, sets
+// response to its zero value. The request is cancelled if ctx is done.
+func (c *Client) do(ctx context.Context, method, baseURL, path string, body interface{}, response interface{}) error {
+	buf, err := c.doRaw(ctx, method, baseURL, path, body)
+	if err != nil || response == nil {
+		return err
+	}
+	if len(bytes.TrimSpace(buf)) == 0 {
+		setZero(response)
+		return nil
+	}
+	if err := json.Unmarshal(buf, &response); err != nil {
+		return fmt.Errorf("%v couldn't unmarshal response: %v (Response was %v)\n", method, err, string(buf))
+	}
+	return nil
+}
+
+// doRaw is like do, but returns the response body undecoded.
+func (c *Client) doRaw(ctx context.Context, method, baseURL, path string, body interface{}) ([]byte, error) {
 	if len(path) > 0 && path[0] == '/' {
-		log.Print("Warning: Do not include / at the start of path")
+		c.logf("Warning: Do not include / at the start of path %v", path)
+	}
+	var reqBody []byte
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("%v couldn't marshal request body: %w\n", method, err)
+		}
+		reqBody = buf
+	}
+	for attempt := 0; ; attempt++ {
+		resp, buf, err := c.send(ctx, method, baseURL+path, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("%v couldn't do request %v: %w\n", method, path, err)
+		}
+		if attempt < c.MaxRetries && shouldRetry(method, resp.StatusCode) {
+			if err := sleepContext(ctx, retryDelay(resp, attempt)); err != nil {
+				return nil, fmt.Errorf("%v couldn't retry request %v: %w\n", method, path, err)
+			}
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, &APIError{
+				Method:     method,
+				URL:        resp.Request.URL.String(),
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+				Body:       string(buf),
+			}
+		}
+		return buf, nil
+	}
+}
+
+// send does a single request and returns the response together with its
+// body, which has already been read and closed.
+func (c *Client) send(ctx context.Context, method, url string, body []byte) (*http.Response, []byte, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.ApiKey == "" && c.email != "" {
+		req.SetBasicAuth(c.email, c.password)
+	} else {
+		req.SetBasicAuth(c.ApiKey, "api_token")
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	if err := c.waitTurn(ctx); err != nil {
+		return nil, nil, err
 	}
-	req, _ := http.NewRequest("GET", TogglApi+path, nil)
-	req.SetBasicAuth(c.ApiKey, "api_token")
+	if c.RequestHook != nil {
+		c.RequestHook(req)
+	}
+	start := time.Now()
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("GET couldn't do request %v: %v\n", path, err)
+		c.logf("%v %v failed after %v: %v", method, req.URL, time.Since(start), err)
+		return nil, nil, err
 	}
-	defer func() {
-		resp.Body.Close()
-	}()
+	c.logf("%v %v %v in %v", method, req.URL, resp.StatusCode, time.Since(start))
+	c.lastMu.Lock()
+	c.lastResp = ResponseInfo{StatusCode: resp.StatusCode, Header: resp.Header.Clone(), Received: time.Now()}
+	c.lastMu.Unlock()
+	defer drainAndClose(resp.Body)
 	buf, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("GET to %v couldn't read response body: %v\n", req.URL, err)
+		return nil, nil, fmt.Errorf("couldn't read response body: %w", err)
 	}
-	if len(buf) == 0 {
-		return fmt.Errorf("GET to %v response had length zero.\n", req.URL)
+	return resp, buf, nil
+}
+
+// shouldRetry reports whether a response status is worth retrying. 429 is
+// always retried. 5xx is retried except for POST, since a POST that failed
+// on the server may still have created something.
+func shouldRetry(method string, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
 	}
-	if err := json.Unmarshal(buf, &response); err != nil {
-		return fmt.Errorf("GET couldn't unmarshal response: %v (Response was %v)\n", err, string(buf))
+	return status >= 500 && method != "POST"
+}
+
+// retryDelay is how long to wait before retrying resp. It honors
+// Retry-After, given in seconds or as a date, and otherwise backs off
+// exponentially from retryBaseDelay.
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		if seconds, err := strconv.Atoi(after); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(after); err == nil {
+			return time.Until(at)
+		}
+	}
+	return retryBaseDelay << uint(attempt)
+}
+
+// retryBaseDelay is the wait before the first retry when the response has no
+// Retry-After header.
+const retryBaseDelay = time.Second
+
+// sleepContext sleeps for d, or returns the context's error if ctx is done
+// first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
 	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		return fmt.Errorf("GET got wrong status code %v\n", resp.Status)
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// acquire blocks until fewer than MaxConcurrency requests are in flight and
+// returns the function that releases the slot again. It gives up with the
+// context's error if ctx is done first.
+func (c *Client) acquire(ctx context.Context) (release func(), err error) {
+	c.semOnce.Do(func() {
+		n := c.MaxConcurrency
+		if n <= 0 {
+			n = DefaultMaxConcurrency
+		}
+		c.sem = make(chan struct{}, n)
+	})
+	select {
+	case c.sem <- struct{}{}:
+		return func() { <-c.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// waitTurn blocks until MinRequestInterval has passed since the previous
+// request was let through, and reserves the next turn. It gives up with the
+// context's error if ctx is done first.
+func (c *Client) waitTurn(ctx context.Context) error {
+	if c.MinRequestInterval <= 0 {
+		return nil
+	}
+	c.rateMu.Lock()
+	now := time.Now()
+	turn := c.nextRequest
+	if turn.Before(now) {
+		turn = now
+	}
+	c.nextRequest = turn.Add(c.MinRequestInterval)
+	c.rateMu.Unlock()
+	return sleepContext(ctx, turn.Sub(now))
+}
+
+// drainAndClose reads whatever is left of body before closing it, so that the
+// connection can be reused even when we bail out early.
+func drainAndClose(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, body)
+	body.Close()
+}
+
+// getData does a GET and decodes the result into v, whether the endpoint wraps
+// it as {"data": ...} or returns it bare.
+func (c *Client) getData(ctx context.Context, path string, v interface{}) error {
+	return c.doData(ctx, "GET", path, nil, v)
+}
+
+// doData is like getData for any method.
+func (c *Client) doData(ctx context.Context, method, path string, body interface{}, v interface{}) error {
+	raw := json.RawMessage{}
+	if err := c.do(ctx, method, c.BaseURL, path, body, &raw); err != nil {
+		return err
+	}
+	return unwrapData(raw, v)
+}
+
+// setZero sets what the pointer v points to to its zero value.
+func setZero(v interface{}) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+	}
+}
+
+// unwrapData decodes buf into v. Some endpoints return {"data": obj} or
+// {"data": [...]} while others return the object or array directly, so the
+// wrapped shape is tried first and the bare shape is the fallback.
+func unwrapData(buf []byte, v interface{}) error {
+	if len(bytes.TrimSpace(buf)) == 0 {
+		setZero(v)
+		return nil
+	}
+	wrapper := struct {
+		Data json.RawMessage
+	}{}
+	if err := json.Unmarshal(buf, &wrapper); err == nil && len(wrapper.Data) > 0 {
+		buf = wrapper.Data
+	}
+	if err := json.Unmarshal(buf, v); err != nil {
+		return fmt.Errorf("Couldn't decode response: %v (Response was %v)\n", err, string(buf))
 	}
 	return nil
 }
@@ -213,42 +1795,4 @@ type TogglTimeEntry struct {
 type TogglTimeEntryResponse struct {
 	Data TogglTimeEntry
 }
-
-type TogglProject struct {
-	ID            int
-	GUID          string
-	WID           int
-	CID           int
-	Name          string
-	Billable      bool
-	IsPrivate     bool `json:"is_private"`
-	Active        bool
-	Template      bool
-	At            time.Time
-	CreatedAt     time.Time `json:"created_at"`
-	Color         string
-	AutoEstimates bool `json:"auto_estimates"`
-	ActualHours   int  `json:"actual_hours"`
-}
-
-type TogglProjectResponse struct {
-	Data TogglProject
-}
-
-type TogglProjectSummary struct {
-	Id int
-	// Items []???
-	Time  int // Duration in milliseconds
-	Title struct {
-		Client   string
-		Color    string
-		HexColor string `json:"hex_color"`
-		Project  string
-	}
-	// TotalCurrencies []Currency `json:"total_currencies"`
-}
-
-type TogglProjectSummariesResponse struct {
-	Data []TogglProjectSummary
-}
 */
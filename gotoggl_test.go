@@ -0,0 +1,1275 @@
+package gotoggl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client pointed at an httptest server running h.
+func newTestClient(t *testing.T, h http.HandlerFunc) (*Client, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+	c := NewClient("token")
+	c.BaseURL = server.URL + "/"
+	c.ReportsURL = server.URL + "/reports/"
+	return c, server
+}
+
+func TestRetryAfterZeroIsRetriedMaxRetriesTimes(t *testing.T) {
+	calls := 0
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	c.MaxRetries = 3
+	_, err := c.Me.Get()
+	if !IsRateLimited(err) {
+		t.Fatalf("got error %v, want rate limited", err)
+	}
+	if calls != c.MaxRetries+1 {
+		t.Errorf("got %v calls, want %v", calls, c.MaxRetries+1)
+	}
+}
+
+func TestRetrySucceedsAfter429(t *testing.T) {
+	calls := 0
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data":{"email":"a@example.com"}}`))
+	})
+	user, err := c.Me.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "a@example.com" || calls != 3 {
+		t.Errorf("got %q after %v calls", user.Email, calls)
+	}
+}
+
+func TestRetryWaitStopsOnCancelledContext(t *testing.T) {
+	calls := 0
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.Me.GetContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want context deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("wait took %v, want it cut short by the context", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("got %v calls, want 1", calls)
+	}
+}
+
+func TestPostIsNotRetriedOn5xx(t *testing.T) {
+	calls := 0
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	if _, err := c.TimeEntries.Start(TimeEntry{Description: "x"}); err == nil {
+		t.Fatal("got no error, want 500")
+	}
+	if calls != 1 {
+		t.Errorf("got %v calls, want 1", calls)
+	}
+}
+
+func TestNewClientWithNilHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"email":"a@example.com"}}`))
+	}))
+	defer server.Close()
+	c := NewClientWithHTTPClient("token", nil)
+	c.BaseURL = server.URL + "/"
+	if _, err := c.Me.Get(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// countConns counts the connections opened to server.
+func countConns(server *httptest.Server) *int32 {
+	var n int32
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&n, 1)
+		}
+	}
+	return &n
+}
+
+func TestErrorResponsesReuseConnection(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"something went wrong, and here is a long body to drain"}`))
+	}))
+	conns := countConns(server)
+	server.Start()
+	defer server.Close()
+	c := NewClient("token")
+	c.BaseURL = server.URL + "/"
+	c.MaxRetries = 0
+	for i := 0; i < 20; i++ {
+		if _, err := c.Me.Get(); err == nil {
+			t.Fatal("got no error, want 500")
+		}
+	}
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Errorf("got %v connections, want 1", n)
+	}
+}
+
+func TestRetriesReuseConnection(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`rate limited`))
+	}))
+	conns := countConns(server)
+	server.Start()
+	defer server.Close()
+	c := NewClient("token")
+	c.BaseURL = server.URL + "/"
+	c.MaxRetries = 3
+	for i := 0; i < 5; i++ {
+		if _, err := c.Me.Get(); !IsRateLimited(err) {
+			t.Fatalf("got error %v, want rate limited", err)
+		}
+	}
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Errorf("got %v connections, want 1", n)
+	}
+}
+
+func TestBaseURLPointsAtServer(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if r.URL.Path != "/me" || !ok || user != "token" || pass != "api_token" {
+			t.Errorf("got %v %v with auth %q:%q", r.Method, r.URL.Path, user, pass)
+		}
+		w.Write([]byte(`{"data":{"email":"a@example.com"}}`))
+	})
+	user, err := c.Me.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "a@example.com" {
+		t.Errorf("got email %q", user.Email)
+	}
+}
+
+func TestRangeAll(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		entries int
+		minReqs int
+	}{
+		{"fits in one request", 10, 1},
+		{"exactly the limit", RangeLimit, 3},
+		{"needs several splits", 2*RangeLimit + 500, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs := 0
+			c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				reqs++
+				from, _ := time.Parse(time.RFC3339, r.URL.Query().Get("start_date"))
+				to, _ := time.Parse(time.RFC3339, r.URL.Query().Get("end_date"))
+				// One entry a minute, and both ends inclusive so
+				// that adjacent windows return duplicates.
+				entries := []map[string]interface{}{}
+				for i := 0; i < tt.entries; i++ {
+					start := base.Add(time.Duration(i) * time.Minute)
+					if !start.Before(from) && !start.After(to) {
+						entries = append(entries, map[string]interface{}{
+							"id":       i + 1,
+							"start":    start,
+							"stop":     start.Add(time.Minute),
+							"duration": 60,
+						})
+					}
+				}
+				json.NewEncoder(w).Encode(entries)
+			})
+			all, err := c.TimeEntries.RangeAll(base, base.Add(time.Duration(tt.entries)*time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != tt.entries {
+				t.Fatalf("got %v entries, want %v", len(all), tt.entries)
+			}
+			for i, te := range all {
+				if te.Id != i+1 {
+					t.Fatalf("entry %v has id %v, want sorted by start", i, te.Id)
+				}
+			}
+			if reqs < tt.minReqs {
+				t.Errorf("got %v requests, want at least %v", reqs, tt.minReqs)
+			}
+		})
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		id   int
+		err  error
+	}{
+		{"running", `{"data":{"id":5,"start":"2020-01-01T09:00:00Z","duration":-1577869200}}`, 5, nil},
+		{"nothing running", `{"data":null}`, 0, ErrNotRunning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/time_entries/current" {
+					t.Errorf("got path %v", r.URL.Path)
+				}
+				w.Write([]byte(tt.body))
+			})
+			te, err := c.TimeEntries.Current()
+			if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if te.Id != tt.id {
+				t.Errorf("got id %v, want %v", te.Id, tt.id)
+			}
+		})
+	}
+}
+
+// fakeTimer is a handler that keeps a single running entry like Toggl does,
+// answering current, get, start and stop. Get always returns the running
+// entry.
+type fakeTimer struct {
+	running *TimeEntry
+	nextId  int
+	started []string
+}
+
+func (ft *fakeTimer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	write := func(te *TimeEntry) {
+		if te == nil {
+			w.Write([]byte(`{"data":null}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+			"id": te.Id, "description": te.Description, "pid": te.ProjectId, "wid": te.WorkspaceId,
+			"start": te.Start, "duration": -te.Start.Unix(),
+		}})
+	}
+	switch {
+	case r.Method == "GET":
+		write(ft.running)
+	case r.Method == "POST" && r.URL.Path == "/time_entries/start":
+		body := struct {
+			TimeEntry TimeEntry `json:"time_entry"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		ft.nextId++
+		te := body.TimeEntry
+		te.Id = ft.nextId
+		te.Start = time.Now().Add(-time.Minute)
+		ft.running = &te
+		ft.started = append(ft.started, te.Description)
+		write(ft.running)
+	case r.Method == "PUT":
+		te := *ft.running
+		ft.running = nil
+		fmt.Fprintf(w, `{"data":{"id":%d,"description":%q,"start":%q,"stop":%q,"duration":60}}`,
+			te.Id, te.Description, te.Start.Format(time.RFC3339), time.Now().Format(time.RFC3339))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	ft := &fakeTimer{}
+	c, _ := newTestClient(t, ft.ServeHTTP)
+	if _, err := c.TimeEntries.Pause(); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("got error %v pausing with nothing running", err)
+	}
+	if _, err := c.TimeEntries.Resume(); !errors.Is(err, ErrNotPaused) {
+		t.Fatalf("got error %v resuming with nothing paused", err)
+	}
+	if _, err := c.TimeEntries.Start(TimeEntry{Description: "Writing", ProjectId: 3}); err != nil {
+		t.Fatal(err)
+	}
+	stopped, err := c.TimeEntries.Pause()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped.Id != 1 || ft.running != nil {
+		t.Fatalf("got stopped %+v, running %+v", stopped, ft.running)
+	}
+	if paused, ok := c.TimeEntries.Paused(); !ok || paused.Description != "Writing" || paused.ProjectId != 3 {
+		t.Errorf("got paused %+v, %v", paused, ok)
+	}
+	resumed, err := c.TimeEntries.Resume()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Id != 2 || resumed.Description != "Writing" || resumed.ProjectId != 3 {
+		t.Errorf("got resumed %+v", resumed)
+	}
+	if _, ok := c.TimeEntries.Paused(); ok {
+		t.Error("entry still paused after Resume")
+	}
+}
+
+func TestStopCurrent(t *testing.T) {
+	ft := &fakeTimer{}
+	c, _ := newTestClient(t, ft.ServeHTTP)
+	if _, err := c.TimeEntries.StopCurrent(); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("got error %v stopping with nothing running", err)
+	}
+	started, err := c.TimeEntries.Start(TimeEntry{Description: "Writing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopped, err := c.TimeEntries.StopCurrent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped.Id != started.Id || stopped.IsRunning() || ft.running != nil {
+		t.Errorf("got stopped %+v, running %+v", stopped, ft.running)
+	}
+}
+
+func TestSwitchTo(t *testing.T) {
+	ft := &fakeTimer{}
+	c, _ := newTestClient(t, ft.ServeHTTP)
+	stopped, started, err := c.TimeEntries.SwitchTo(TimeEntry{Description: "First"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped != nil || started.Description != "First" {
+		t.Fatalf("got stopped %+v, started %+v", stopped, started)
+	}
+	stopped, started, err = c.TimeEntries.SwitchTo(TimeEntry{Description: "Second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped == nil || stopped.Description != "First" || started.Description != "Second" {
+		t.Fatalf("got stopped %+v, started %+v", stopped, started)
+	}
+	if ft.running == nil || ft.running.Id != started.Id {
+		t.Errorf("got running %+v, want %v", ft.running, started.Id)
+	}
+}
+
+func TestStartByProjectName(t *testing.T) {
+	ft := &fakeTimer{}
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/workspaces/7/projects" {
+			w.Write([]byte(`[{"id":3,"name":"Backend"}]`))
+			return
+		}
+		ft.ServeHTTP(w, r)
+	})
+	started, err := c.TimeEntries.StartByProjectName("Backend", 7, "Refactoring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.ProjectId != 3 || started.WorkspaceId != 7 || started.Description != "Refactoring" {
+		t.Errorf("got %+v", started)
+	}
+	if _, err := c.TimeEntries.StartByProjectName("Frontend", 7, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want ErrNotFound", err)
+	}
+}
+
+func TestDurationMarshalJSON(t *testing.T) {
+	buf, err := json.Marshal(Duration{90*time.Minute + 500*time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "5400" {
+		t.Errorf("got %s, want 5400", buf)
+	}
+}
+
+func TestTimeEntryRoundTrip(t *testing.T) {
+	start := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"finished", `{"id":1,"wid":2,"pid":3,"start":"2020-01-01T09:00:00Z","stop":"2020-01-01T10:00:00Z","duration":3600,"tags":["a"]}`},
+		{"running", fmt.Sprintf(`{"id":1,"wid":2,"pid":3,"start":"2020-01-01T09:00:00Z","duration":%d}`, -start.Unix())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := TimeEntry{}
+			if err := json.Unmarshal([]byte(tt.json), &want); err != nil {
+				t.Fatal(err)
+			}
+			buf, err := json.Marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := TimeEntry{}
+			if err := json.Unmarshal(buf, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Id != 1 || got.WorkspaceId != 2 || got.ProjectId != 3 || got.RawDuration != want.RawDuration || got.IsRunning() != want.IsRunning() {
+				t.Errorf("got %+v from %s, want %+v", got, buf, want)
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name  string
+		agent string
+		want  string
+	}{
+		{"default", "", UserAgent},
+		{"override", "my-tool/1.0", "my-tool/1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("User-Agent"); got != tt.want {
+					t.Errorf("got User-Agent %q, want %q", got, tt.want)
+				}
+				if r.URL.Path == "/reports/summary" && r.URL.Query().Get("user_agent") != tt.want {
+					t.Errorf("got user_agent %q, want %q", r.URL.Query().Get("user_agent"), tt.want)
+				}
+				w.Write([]byte(`{}`))
+			})
+			if tt.agent != "" {
+				c.UserAgent = tt.agent
+			}
+			if _, err := c.Me.Get(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Reports.Summary(SummaryParams{WorkspaceId: 1}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestUpdateTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		ids    []int
+		action string
+		body   string
+		want   int
+		err    bool
+	}{
+		{"several", []int{1, 2}, "add", `{"data":[{"id":1,"tags":["x"]},{"id":2,"tags":["x"]}]}`, 2, false},
+		{"single", []int{1}, "remove", `{"data":{"id":1,"tags":[]}}`, 1, false},
+		{"bad action", []int{1}, "replace", "", 0, true},
+		{"no ids", nil, "add", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				body := struct {
+					TimeEntry map[string]interface{} `json:"time_entry"`
+				}{}
+				json.NewDecoder(r.Body).Decode(&body)
+				if r.Method != "PUT" || r.URL.Path != "/time_entries/"+joinIds(tt.ids) || body.TimeEntry["tag_action"] != tt.action {
+					t.Errorf("got %v %v with %v", r.Method, r.URL.Path, body)
+				}
+				w.Write([]byte(tt.body))
+			})
+			entries, err := c.TimeEntries.UpdateTags(tt.ids, []string{"x"}, tt.action)
+			if (err != nil) != tt.err {
+				t.Fatalf("got error %v", err)
+			}
+			if len(entries) != tt.want {
+				t.Errorf("got %v entries, want %v", len(entries), tt.want)
+			}
+			if tt.err && calls != 0 {
+				t.Errorf("got %v requests for invalid arguments", calls)
+			}
+		})
+	}
+}
+
+func TestUpdateTagsDetailed(t *testing.T) {
+	paths := []string{}
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/time_entries/1,2":
+			w.Write([]byte(`{"data":[{"id":1,"tags":["x"]}]}`))
+		case "/time_entries/1,3,4", "/time_entries/4":
+			w.WriteHeader(http.StatusForbidden)
+		case "/time_entries/1", "/time_entries/3":
+			fmt.Fprintf(w, `{"data":{"id":%v,"tags":["x"]}}`, strings.TrimPrefix(r.URL.Path, "/time_entries/"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	result, err := c.TimeEntries.UpdateTagsDetailed([]int{1, 2}, []string{"x"}, "add")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Updated) != 1 || result.Updated[0].Id != 1 || len(result.Failed) != 1 || !errors.Is(result.Failed[2], ErrNotFound) {
+		t.Errorf("got %+v", result)
+	}
+
+	paths = nil
+	apiErr := &APIError{}
+	result, err = c.TimeEntries.UpdateTagsDetailed([]int{1, 3, 4}, []string{"x"}, "add")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Updated) != 2 || result.Updated[0].Id != 1 || result.Updated[1].Id != 3 || len(result.Failed) != 1 || !errors.As(result.Failed[4], &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("got %+v", result)
+	}
+	if want := []string{"/time_entries/1,3,4", "/time_entries/1", "/time_entries/3", "/time_entries/4"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got requests %v, want %v", paths, want)
+	}
+
+	if _, err := c.TimeEntries.UpdateTagsDetailed(nil, []string{"x"}, "add"); err == nil {
+		t.Error("got no error for no ids")
+	}
+}
+
+func TestUpdateIfUnmodified(t *testing.T) {
+	at := "2020-01-02T10:00:00Z"
+	puts := 0
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/time_entries/1" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == "PUT" {
+			puts++
+			body := struct {
+				TimeEntry map[string]interface{} `json:"time_entry"`
+			}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.TimeEntry["description"] != "Edited" || body.TimeEntry["pid"] != nil || body.TimeEntry["duration"] != 3600.0 {
+				t.Errorf("got body %v", body.TimeEntry)
+			}
+			at = "2020-01-02T11:00:00Z"
+		}
+		fmt.Fprintf(w, `{"data":{"id":1,"description":"Edited","start":"2020-01-02T09:00:00Z","stop":"2020-01-02T10:00:00Z","duration":3600,"at":%q}}`, at)
+	})
+	read, err := c.TimeEntries.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read.Description = "Edited"
+	updated, err := c.TimeEntries.UpdateIfUnmodified(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if puts != 1 || updated.At.Equal(read.At) {
+		t.Errorf("got %v updates, %+v", puts, updated)
+	}
+	if _, err := c.TimeEntries.UpdateIfUnmodified(read); !errors.Is(err, ErrConflict) {
+		t.Errorf("got error %v updating a stale entry, want ErrConflict", err)
+	}
+	if puts != 1 {
+		t.Errorf("got %v updates, want the stale one skipped", puts)
+	}
+}
+
+func TestElapsed(t *testing.T) {
+	start := time.Now().Add(-90 * time.Minute)
+	running := TimeEntry{}
+	body := fmt.Sprintf(`{"id":1,"start":%q,"duration":%d}`, start.Format(time.RFC3339), -start.Unix())
+	if err := json.Unmarshal([]byte(body), &running); err != nil {
+		t.Fatal(err)
+	}
+	if d := running.Elapsed(); d < 89*time.Minute || d > 91*time.Minute {
+		t.Errorf("got %v for a running entry, want about 90m", d)
+	}
+	done := TimeEntry{Start: start, Stop: start.Add(time.Hour), Duration: Duration{time.Hour}}
+	if d := done.Elapsed(); d != time.Hour {
+		t.Errorf("got %v for a finished entry, want 1h", d)
+	}
+}
+
+func TestIsRunning(t *testing.T) {
+	start := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry TimeEntry
+		want  bool
+	}{
+		{"finished", TimeEntry{Start: start, Stop: start.Add(time.Hour), RawDuration: 3600}, false},
+		{"no stop", TimeEntry{Start: start}, true},
+		{"negative duration", TimeEntry{Start: start, Stop: start, RawDuration: -start.Unix()}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.IsRunning(); got != tt.want {
+			t.Errorf("%v: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRequestHook(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["tag"]["name"] != "x" {
+			t.Errorf("server got body %v after the hook read it", body)
+		}
+		w.Write([]byte(`{"data":{"id":1,"name":"x"}}`))
+	})
+	var got *http.Request
+	var gotBody map[string]map[string]interface{}
+	c.RequestHook = func(req *http.Request) {
+		got = req
+		body, err := req.GetBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewDecoder(body).Decode(&gotBody)
+	}
+	if _, err := c.Tags.Create(7, "x"); err != nil {
+		t.Fatal(err)
+	}
+	user, pass, _ := got.BasicAuth()
+	if got.Method != "POST" || got.URL.Path != "/tags" || user != "token" || pass != "api_token" {
+		t.Errorf("hook got %v %v with auth %q:%q", got.Method, got.URL.Path, user, pass)
+	}
+	if gotBody["tag"]["name"] != "x" || gotBody["tag"]["wid"] != 7.0 {
+		t.Errorf("hook got body %v", gotBody)
+	}
+}
+
+func TestRangeInWorkspace(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":1,"wid":1},{"id":2,"wid":2},{"id":3,"workspace_id":1}]`))
+	})
+	entries, err := c.TimeEntries.RangeInWorkspace(1, time.Now().Add(-time.Hour), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Id != 1 || entries[1].Id != 3 {
+		t.Errorf("got %+v", entries)
+	}
+}
+
+func TestNewClientAPIKey(t *testing.T) {
+	if c := NewClient("  abc123\n"); c.ApiKey != "abc123" {
+		t.Errorf("got key %q, want it trimmed", c.ApiKey)
+	}
+	for _, key := range []string{"", " \n"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewClient(%q) did not panic", key)
+				}
+			}()
+			NewClient(key)
+		}()
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(APITokenEnv, "")
+	if _, err := NewClientFromEnv(); err == nil {
+		t.Error("got no error without a token")
+	}
+	t.Setenv(APITokenEnv, " abc123 ")
+	c, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ApiKey != "abc123" {
+		t.Errorf("got key %q", c.ApiKey)
+	}
+}
+
+func TestNewClientWithCredentials(t *testing.T) {
+	auths := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		auths = append(auths, user+":"+pass)
+		w.Write([]byte(`{"data":{"email":"a@example.com","api_token":"abc123"}}`))
+	}))
+	defer server.Close()
+	c := NewClientWithCredentials("a@example.com", "secret")
+	c.BaseURL = server.URL + "/"
+	user, err := c.Me.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ApiToken != "abc123" || c.ApiKey != "abc123" {
+		t.Errorf("got token %q, client key %q", user.ApiToken, c.ApiKey)
+	}
+	if _, err := c.Me.Get(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a@example.com:secret", "abc123:api_token"}
+	if !reflect.DeepEqual(auths, want) {
+		t.Errorf("got auths %v, want %v", auths, want)
+	}
+}
+
+func TestTotalsByProject(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id":1,"pid":1,"start":"2020-01-01T09:00:00Z","stop":"2020-01-01T10:00:00Z","duration":3600},
+			{"id":2,"pid":1,"start":"2020-01-31T23:00:00Z","stop":"2020-02-01T01:00:00Z","duration":7200},
+			{"id":3,"start":"2020-01-02T09:00:00Z","stop":"2020-01-02T09:30:00Z","duration":1800},
+			{"id":4,"pid":2,"start":"2020-01-31T22:00:00Z","duration":-1580508000}
+		]`))
+	})
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	totals, err := c.TimeEntries.TotalsByProject(start, start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]time.Duration{1: 2 * time.Hour, 0: 30 * time.Minute}
+	if !reflect.DeepEqual(totals, want) {
+		t.Errorf("got %v, want %v", totals, want)
+	}
+}
+
+func TestLastResponse(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Toggl-Quota-Remaining", "29")
+		w.Header().Set("X-Toggl-Quota-Resets-In", "15")
+		w.Write([]byte(`{"data":{}}`))
+	})
+	if got := c.LastResponse(); got.StatusCode != 0 {
+		t.Errorf("got %+v before any request", got)
+	}
+	if _, err := c.Me.Get(); err != nil {
+		t.Fatal(err)
+	}
+	last := c.LastResponse()
+	remaining, ok := last.QuotaRemaining()
+	if last.StatusCode != http.StatusOK || !ok || remaining != 29 {
+		t.Errorf("got status %v, quota %v, %v", last.StatusCode, remaining, ok)
+	}
+	if resets, ok := last.QuotaResetsIn(); !ok || resets != 15*time.Second {
+		t.Errorf("got resets in %v, %v", resets, ok)
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []int
+		body string
+		want []int
+	}{
+		{"several", []int{1, 2}, `{"data":[{"id":1},{"id":2}]}`, []int{1, 2}},
+		{"one", []int{1}, `{"data":{"id":1}}`, []int{1}},
+		{"not found", []int{9}, `{"data":null}`, []int{}},
+		{"none", nil, "", []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.URL.Path != "/time_entries/"+joinIds(tt.ids) {
+					t.Errorf("got path %v", r.URL.Path)
+				}
+				w.Write([]byte(tt.body))
+			})
+			entries, err := c.TimeEntries.GetMany(tt.ids)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []int{}
+			for _, te := range entries {
+				got = append(got, te.Id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got ids %v, want %v", got, tt.want)
+			}
+			if len(tt.ids) == 0 && calls != 0 {
+				t.Errorf("got %v requests for no ids", calls)
+			}
+		})
+	}
+}
+
+func TestTimeEntryAt(t *testing.T) {
+	tests := []struct {
+		json string
+		want time.Time
+		err  bool
+	}{
+		{`{"id":1,"at":"2020-01-02T03:04:05+00:00"}`, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{`{"id":1}`, time.Time{}, false},
+		{`{"id":1,"at":""}`, time.Time{}, false},
+		{`{"id":1,"at":null}`, time.Time{}, false},
+		{`{"id":1,"at":"yesterday"}`, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		te := TimeEntry{}
+		err := json.Unmarshal([]byte(tt.json), &te)
+		if (err != nil) != tt.err || !te.At.Equal(tt.want) {
+			t.Errorf("%s: got %v, %v", tt.json, te.At, err)
+		}
+	}
+}
+
+func TestModifiedBetween(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id":1,"start":"2019-12-01T09:00:00Z","at":"2020-01-05T00:00:00Z"},
+			{"id":2,"start":"2020-01-02T09:00:00Z","at":"2020-01-03T00:00:00Z"},
+			{"id":3,"start":"2020-01-02T10:00:00Z","at":"2019-12-31T00:00:00Z"},
+			{"id":4,"start":"2020-01-02T11:00:00Z"}
+		]`))
+	})
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries, err := c.TimeEntries.ModifiedBetween(start, start.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Id != 2 || entries[1].Id != 1 {
+		t.Errorf("got %+v", entries)
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/me" || q.Get("with_related_data") != "true" || q.Get("since") != "1577836800" {
+			t.Errorf("got %v with %v", r.URL.Path, q)
+		}
+		w.Write([]byte(`{"since":1577836800,"data":{"time_entries":[
+			{"id":1,"at":"2020-01-03T00:00:00Z"},
+			{"id":2,"at":"2020-01-02T00:00:00Z"},
+			{"id":3,"at":"2019-12-31T23:59:59Z"}
+		]}}`))
+	})
+	entries, err := c.TimeEntries.ChangedSince(since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Id != 2 || entries[1].Id != 1 {
+		t.Errorf("got %+v", entries)
+	}
+}
+
+func TestHasTags(t *testing.T) {
+	te := TimeEntry{Tags: []string{"Meeting", "client"}}
+	tests := []struct {
+		tags []string
+		all  bool
+		want bool
+	}{
+		{[]string{"meeting"}, false, true},
+		{[]string{"other", "CLIENT"}, false, true},
+		{[]string{"other"}, false, false},
+		{nil, false, false},
+		{[]string{"meeting", "client"}, true, true},
+		{[]string{"meeting", "other"}, true, false},
+		{nil, true, true},
+	}
+	for _, tt := range tests {
+		if got := hasTags(te, tt.tags, tt.all); got != tt.want {
+			t.Errorf("hasTags(%v, all %v) = %v, want %v", tt.tags, tt.all, got, tt.want)
+		}
+	}
+}
+
+func TestRangeByTag(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":1,"tags":["a","b"]},{"id":2,"tags":["B"]},{"id":3}]`))
+	})
+	any, err := c.TimeEntries.RangeByTag(time.Now().Add(-time.Hour), time.Now(), []string{"b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := c.TimeEntries.RangeByAllTags(time.Now().Add(-time.Hour), time.Now(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(any) != 2 || len(all) != 1 || all[0].Id != 1 {
+		t.Errorf("got any %+v, all %+v", any, all)
+	}
+}
+
+func TestStartIfNotRunning(t *testing.T) {
+	ft := &fakeTimer{}
+	c, _ := newTestClient(t, ft.ServeHTTP)
+	first, started, err := c.TimeEntries.StartIfNotRunning(TimeEntry{Description: "First"})
+	if err != nil || !started {
+		t.Fatalf("got started %v, error %v", started, err)
+	}
+	again, started, err := c.TimeEntries.StartIfNotRunning(TimeEntry{Description: "Second"})
+	if err != nil || started {
+		t.Fatalf("got started %v, error %v", started, err)
+	}
+	if again.Id != first.Id || again.Description != "First" || len(ft.started) != 1 {
+		t.Errorf("got %+v, started %v", again, ft.started)
+	}
+}
+
+func TestGetRaw(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		if user != "token" || r.Header.Get("User-Agent") != UserAgent {
+			t.Errorf("got auth %q, agent %q", user, r.Header.Get("User-Agent"))
+		}
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data":{"anything":[1,2]}}`))
+	})
+	buf, err := c.GetRaw("workspaces/1/anything")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != `{"data":{"anything":[1,2]}}` {
+		t.Errorf("got %s", buf)
+	}
+	if _, err := c.GetRaw("missing"); !IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+}
+
+func TestEmptyBodyDecodesToZero(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	tags := []Tag{{Name: "stale"}}
+	if err := c.GET("workspaces/1/tags", &tags); err != nil {
+		t.Fatal(err)
+	}
+	if tags != nil {
+		t.Errorf("got %v, want the zero value", tags)
+	}
+	list, err := c.Tags.List(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Errorf("got %v", list)
+	}
+	if _, err := c.Me.Get(); err != nil {
+		t.Errorf("got error %v for an empty user", err)
+	}
+}
+
+func TestRangeConcurrent(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var requests int32
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		from, _ := time.Parse(time.RFC3339, r.URL.Query().Get("start_date"))
+		to, _ := time.Parse(time.RFC3339, r.URL.Query().Get("end_date"))
+		if from.Month() == time.March {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// One entry a day, with both ends inclusive so that months overlap.
+		entries := []map[string]interface{}{}
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			entries = append(entries, map[string]interface{}{"id": day.YearDay(), "start": day})
+		}
+		json.NewEncoder(w).Encode(entries)
+	})
+	entries, err := c.TimeEntries.RangeConcurrent(base, base.AddDate(0, 2, 0), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 61 || entries[0].Id != 1 || entries[60].Id != 61 {
+		t.Errorf("got %v entries", len(entries))
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("got %v requests, want 2", n)
+	}
+	_, err = c.TimeEntries.RangeConcurrent(base, base.AddDate(0, 4, 0), 2)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("got error %v, want the failing month's 400", err)
+	}
+}
+
+func TestRangeConcurrentMonths(t *testing.T) {
+	var mu sync.Mutex
+	windows := []string{}
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		windows = append(windows, r.URL.Query().Get("start_date")+" "+r.URL.Query().Get("end_date"))
+		mu.Unlock()
+		w.Write([]byte(`[]`))
+	})
+	start := time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC)
+	if _, err := c.TimeEntries.RangeConcurrent(start, time.Date(2020, 3, 10, 0, 0, 0, 0, time.UTC), 2); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(windows)
+	want := []string{
+		"2020-01-31T12:00:00Z 2020-02-01T00:00:00Z",
+		"2020-02-01T00:00:00Z 2020-03-01T00:00:00Z",
+		"2020-03-01T00:00:00Z 2020-03-10T00:00:00Z",
+	}
+	if !reflect.DeepEqual(windows, want) {
+		t.Errorf("got windows %v, want %v", windows, want)
+	}
+}
+
+func TestMinRequestInterval(t *testing.T) {
+	var mu sync.Mutex
+	starts := []time.Time{}
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		w.Write([]byte(`[]`))
+	})
+	c.MinRequestInterval = 50 * time.Millisecond
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Tags.List(1)
+		}()
+	}
+	wg.Wait()
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	if len(starts) != 4 {
+		t.Fatalf("got %v requests", len(starts))
+	}
+	if d := starts[3].Sub(starts[0]); d < 140*time.Millisecond {
+		t.Errorf("4 requests took %v, want at least 3 intervals", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.MinRequestInterval = time.Hour
+	c.Tags.ListContext(ctx, 1)
+	cancel()
+	if _, err := c.Tags.ListContext(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v waiting for a turn with a cancelled context", err)
+	}
+}
+
+func TestDurationUnmarshalJSON(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name string
+		json string
+		want time.Duration
+		err  bool
+	}{
+		{"number", `3600`, time.Hour, false},
+		{"string", `"3600"`, time.Hour, false},
+		{"running", fmt.Sprint(-start.Unix()), time.Hour, false},
+		{"running string", fmt.Sprintf(`"%d"`, -start.Unix()), time.Hour, false},
+		{"null", `null`, 0, false},
+		{"garbage", `"an hour"`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Duration{}
+			err := json.Unmarshal([]byte(tt.json), &d)
+			if (err != nil) != tt.err {
+				t.Fatalf("got error %v", err)
+			}
+			if diff := d.Duration - tt.want; diff < -time.Second || diff > time.Second {
+				t.Errorf("got %v, want %v", d.Duration, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeEntryStringDuration(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	te := TimeEntry{}
+	body := fmt.Sprintf(`{"id":1,"start":%q,"duration":"%d"}`, start.Format(time.RFC3339), -start.Unix())
+	if err := json.Unmarshal([]byte(body), &te); err != nil {
+		t.Fatal(err)
+	}
+	if te.RawDuration != -start.Unix() || !te.IsRunning() {
+		t.Errorf("got %+v", te)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id":1,"description":"Code review for #12"},
+			{"id":2,"description":"Lunch"},
+			{"id":3,"description":"CODE REVIEW"},
+			{"id":4}
+		]`))
+	})
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"code review", []int{1, 3}},
+		{"lunch", []int{2}},
+		{"standup", []int{}},
+		{"", []int{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		entries, err := c.TimeEntries.Search(time.Now().Add(-time.Hour), time.Now(), tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []int{}
+		for _, te := range entries {
+			got = append(got, te.Id)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestAddRemoveTags(t *testing.T) {
+	tags := []string{"a", "b"}
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			TimeEntry struct {
+				Tags      []string
+				TagAction string `json:"tag_action"`
+			} `json:"time_entry"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Method != "PUT" || r.URL.Path != "/time_entries/5" {
+			t.Errorf("got %v %v", r.Method, r.URL.Path)
+		}
+		for _, tag := range body.TimeEntry.Tags {
+			if body.TimeEntry.TagAction == "add" && !hasTags(TimeEntry{Tags: tags}, []string{tag}, false) {
+				tags = append(tags, tag)
+			}
+			if body.TimeEntry.TagAction == "remove" {
+				kept := []string{}
+				for _, have := range tags {
+					if have != tag {
+						kept = append(kept, have)
+					}
+				}
+				tags = kept
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"id": 5, "tags": tags}})
+	})
+	te, err := c.TimeEntries.AddTags(5, []string{"c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(te.Tags, []string{"a", "b", "c"}) {
+		t.Errorf("got tags %v after add", te.Tags)
+	}
+	te, err = c.TimeEntries.RemoveTags(5, []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(te.Tags, []string{"b", "c"}) {
+		t.Errorf("got tags %v after remove", te.Tags)
+	}
+}
+
+func TestStartFillsCreatedWith(t *testing.T) {
+	tests := []struct {
+		name        string
+		userAgent   string
+		createdWith string
+		want        string
+	}{
+		{"default", "", "", UserAgent},
+		{"client user agent", "my-tool/1.0", "", "my-tool/1.0"},
+		{"set by caller", "my-tool/1.0", "other", "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body := struct {
+					TimeEntry newTimeEntry `json:"time_entry"`
+				}{}
+				json.NewDecoder(r.Body).Decode(&body)
+				if body.TimeEntry.CreatedWith == "" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`"created_with needs to be provided"`))
+					return
+				}
+				fmt.Fprintf(w, `{"data":{"id":1,"created_with":%q}}`, body.TimeEntry.CreatedWith)
+			})
+			if tt.userAgent != "" {
+				c.UserAgent = tt.userAgent
+			}
+			started, err := c.TimeEntries.Start(TimeEntry{Description: "x", CreatedWith: tt.createdWith})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if started.CreatedWith != tt.want {
+				t.Errorf("got created_with %q, want %q", started.CreatedWith, tt.want)
+			}
+		})
+	}
+}
+
+// testLogger collects logged lines.
+type testLogger struct{ lines []string }
+
+func (tl *testLogger) Printf(format string, v ...interface{}) {
+	tl.lines = append(tl.lines, fmt.Sprintf(format, v...))
+}
+
+func TestLogger(t *testing.T) {
+	calls := 0
+	c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data":{}}`))
+	})
+	if _, err := c.Me.Get(); err != nil {
+		t.Fatal(err)
+	}
+	logger := &testLogger{}
+	c.Logger = logger
+	calls = 0
+	if _, err := c.Me.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.lines) != 2 {
+		t.Fatalf("got lines %q, want one per attempt", logger.lines)
+	}
+	prefix := "GET " + server.URL + "/me "
+	if !strings.HasPrefix(logger.lines[0], prefix+"429 in ") || !strings.HasPrefix(logger.lines[1], prefix+"200 in ") {
+		t.Errorf("got lines %q", logger.lines)
+	}
+}
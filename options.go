@@ -0,0 +1,73 @@
+package gotoggl
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a client made with NewClientWithOptions.
+type Option func(*Client)
+
+// NewClientWithOptions creates a new Toggl API client using an API key,
+// configured by opts in order. Without options it is the same as NewClient.
+// The key is trimmed, and an empty key panics, like with NewClient.
+func NewClientWithOptions(apiKey string, opts ...Option) *Client {
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		panic("gotoggl: empty API key")
+	}
+	c := newClient(apiKey, nil)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithHTTPClient makes the client send its requests with hc, for a custom
+// transport or proxy. A nil hc is ignored.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.client = hc
+		}
+	}
+}
+
+// WithBaseURL sets the main API URL, which must end with a slash.
+func WithBaseURL(url string) Option {
+	return func(c *Client) { c.BaseURL = url }
+}
+
+// WithReportsURL sets the reports API URL, which must end with a slash.
+func WithReportsURL(url string) Option {
+	return func(c *Client) { c.ReportsURL = url }
+}
+
+// WithUserAgent sets the User-Agent sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.UserAgent = userAgent }
+}
+
+// WithMaxRetries sets how many times a request is retried, see
+// Client.MaxRetries.
+func WithMaxRetries(n int) Option {
+	return func(c *Client) { c.MaxRetries = n }
+}
+
+// WithMinRequestInterval spaces the client's requests by at least d, see
+// Client.MinRequestInterval.
+func WithMinRequestInterval(d time.Duration) Option {
+	return func(c *Client) { c.MinRequestInterval = d }
+}
+
+// WithTimeout sets the timeout of the client's requests. It applies to a
+// copy of the HTTP client, so one passed to WithHTTPClient is not changed.
+// Use it after WithHTTPClient.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		hc := *c.client
+		hc.Timeout = d
+		c.client = &hc
+	}
+}
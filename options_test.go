@@ -0,0 +1,44 @@
+package gotoggl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewClientWithOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("User-Agent"); got != "my-tool/1.0" {
+			t.Errorf("got User-Agent %q", got)
+		}
+		w.Write([]byte(`{"data":{"email":"a@example.com"}}`))
+	}))
+	defer server.Close()
+	hc := &http.Client{Timeout: time.Minute}
+	c := NewClientWithOptions(" token ",
+		WithHTTPClient(hc),
+		WithTimeout(5*time.Second),
+		WithBaseURL(server.URL+"/"),
+		WithReportsURL(server.URL+"/reports/"),
+		WithUserAgent("my-tool/1.0"),
+		WithMaxRetries(1),
+		WithMinRequestInterval(time.Millisecond),
+	)
+	if c.ApiKey != "token" || c.MaxRetries != 1 || c.MinRequestInterval != time.Millisecond || c.ReportsURL != server.URL+"/reports/" {
+		t.Errorf("got %+v", c)
+	}
+	if c.client.Timeout != 5*time.Second || hc.Timeout != time.Minute {
+		t.Errorf("got timeout %v, and %v on the passed client", c.client.Timeout, hc.Timeout)
+	}
+	if _, err := c.Me.Get(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	c := NewClient("token")
+	if c.BaseURL != TogglApi || c.UserAgent != UserAgent || c.MaxRetries != DefaultMaxRetries || c.client.Timeout != DefaultTimeout {
+		t.Errorf("got %+v", c)
+	}
+}
@@ -0,0 +1,303 @@
+package gotoggl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Project contains the data returned for a single project.
+type Project struct {
+	Id            int
+	Guid          string
+	WorkspaceId   int `json:"wid"`
+	ClientId      int `json:"cid"`
+	Name          string
+	Billable      bool
+	IsPrivate     bool `json:"is_private"`
+	Active        bool
+	Template      bool
+	At            time.Time
+	CreatedAt     time.Time `json:"created_at"`
+	Color         string
+	HexColor      string `json:"hex_color"`
+	AutoEstimates bool   `json:"auto_estimates"`
+	ActualHours   int    `json:"actual_hours"`
+}
+
+// UnmarshalJSON decodes a project from either the v8 or the v9 API. v8 uses
+// wid and cid while v9 uses workspace_id and client_id.
+func (p *Project) UnmarshalJSON(data []byte) error {
+	type project Project
+	aux := struct {
+		*project
+		V9WorkspaceId int `json:"workspace_id"`
+		V9ClientId    int `json:"client_id"`
+	}{project: (*project)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if p.WorkspaceId == 0 {
+		p.WorkspaceId = aux.V9WorkspaceId
+	}
+	if p.ClientId == 0 {
+		p.ClientId = aux.V9ClientId
+	}
+	return nil
+}
+
+// projectColors are the hex colors of the v8 API's color indexes.
+var projectColors = []string{
+	"#4dc3ff", "#bc85e6", "#df7baa", "#f68d38", "#b27636",
+	"#8ab734", "#14a88e", "#268bb5", "#6668b4", "#a4506c",
+	"#67412c", "#3c6526", "#094558", "#bc2d07", "#999999",
+}
+
+// HexColorOrIndex returns the project's hex color. Projects that only have
+// the v8 color index get the hex color of that index. It returns "" for a
+// project with neither.
+func (p Project) HexColorOrIndex() string {
+	if p.HexColor != "" {
+		return p.HexColor
+	}
+	i, err := strconv.Atoi(p.Color)
+	if err != nil || i < 0 || i >= len(projectColors) {
+		return ""
+	}
+	return projectColors[i]
+}
+
+// ActualTime is ActualHours as a duration. Toggl maintains actual_hours on
+// the server from the project's entries, and only in whole hours.
+func (p Project) ActualTime() time.Duration {
+	return time.Duration(p.ActualHours) * time.Hour
+}
+
+// ReconcileActualTime sums the finished entries in the project and compares
+// the total to ActualTime. entries should be every entry of the project, and
+// entries of other projects are ignored. Since ActualHours is in whole hours,
+// the two are consistent when they differ by less than an hour.
+func (p Project) ReconcileActualTime(entries []TimeEntry) (tracked time.Duration, consistent bool) {
+	for _, te := range entries {
+		if te.ProjectId == p.Id && !te.IsRunning() {
+			tracked += te.Duration.Duration
+		}
+	}
+	diff := p.ActualTime() - tracked
+	return tracked, diff > -time.Hour && diff < time.Hour
+}
+
+// ProjectsService accesses /projects
+type ProjectsService struct {
+	client *Client
+
+	mu     sync.Mutex
+	cached map[int][]Project
+}
+
+// Get returns details of a single project
+func (ps *ProjectsService) Get(id int) (Project, error) {
+	return ps.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get with a context.
+func (ps *ProjectsService) GetContext(ctx context.Context, id int) (Project, error) {
+	project := Project{}
+	err := ps.client.getData(ctx, fmt.Sprintf("projects/%d", id), &project)
+	if err != nil {
+		return Project{}, fmt.Errorf("Couldn't get project %v: %w\n", id, err)
+	}
+	return project, nil
+}
+
+// List returns the projects in a workspace
+func (ps *ProjectsService) List(workspaceId int) ([]Project, error) {
+	return ps.ListContext(context.Background(), workspaceId)
+}
+
+// ListContext is like List with a context.
+func (ps *ProjectsService) ListContext(ctx context.Context, workspaceId int) ([]Project, error) {
+	projects := []Project{}
+	err := ps.client.getData(ctx, fmt.Sprintf("workspaces/%d/projects", workspaceId), &projects)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get projects: %w\n", err)
+	}
+	return projects, nil
+}
+
+// CachedList is like List, but only asks Toggl the first time for each
+// workspace. Later calls return the same projects, so projects created since
+// the first call are missing.
+func (ps *ProjectsService) CachedList(workspaceId int) ([]Project, error) {
+	return ps.CachedListContext(context.Background(), workspaceId)
+}
+
+// CachedListContext is like CachedList with a context.
+func (ps *ProjectsService) CachedListContext(ctx context.Context, workspaceId int) ([]Project, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if projects, ok := ps.cached[workspaceId]; ok {
+		return projects, nil
+	}
+	projects, err := ps.ListContext(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	if ps.cached == nil {
+		ps.cached = map[int][]Project{}
+	}
+	ps.cached[workspaceId] = projects
+	return projects, nil
+}
+
+// ByName returns the project in a workspace with the given name, ignoring
+// case, looked up with CachedList. An exact match wins over matches that
+// only differ in case. A name that matches no project returns an error
+// wrapping ErrNotFound, and one that matches several an error listing them.
+func (ps *ProjectsService) ByName(workspaceId int, name string) (Project, error) {
+	return ps.ByNameContext(context.Background(), workspaceId, name)
+}
+
+// ByNameContext is like ByName with a context.
+func (ps *ProjectsService) ByNameContext(ctx context.Context, workspaceId int, name string) (Project, error) {
+	projects, err := ps.CachedListContext(ctx, workspaceId)
+	if err != nil {
+		return Project{}, fmt.Errorf("Couldn't find project %q: %w\n", name, err)
+	}
+	exact, folded := []Project{}, []Project{}
+	for _, p := range projects {
+		if p.Name == name {
+			exact = append(exact, p)
+		} else if strings.EqualFold(p.Name, name) {
+			folded = append(folded, p)
+		}
+	}
+	matches := exact
+	if len(matches) == 0 {
+		matches = folded
+	}
+	switch len(matches) {
+	case 0:
+		return Project{}, fmt.Errorf("Couldn't find project %q in workspace %v: %w\n", name, workspaceId, ErrNotFound)
+	case 1:
+		return matches[0], nil
+	}
+	ids := []string{}
+	for _, p := range matches {
+		ids = append(ids, fmt.Sprintf("%q (%d)", p.Name, p.Id))
+	}
+	return Project{}, fmt.Errorf("Project name %q is ambiguous in workspace %v: %s\n", name, workspaceId, strings.Join(ids, ", "))
+}
+
+// TaskStats counts the tasks of a project, for showing e.g. "12 of 20 tasks
+// done". Done tasks are the ones that are no longer active.
+type TaskStats struct {
+	Total int
+	Done  int
+}
+
+// TaskStats counts the tasks of a project and how many of them are done,
+// from the project's full task list. Like the tasks endpoints it needs a
+// premium workspace.
+func (ps *ProjectsService) TaskStats(projectId int) (TaskStats, error) {
+	return ps.TaskStatsContext(context.Background(), projectId)
+}
+
+// TaskStatsContext is like TaskStats with a context.
+func (ps *ProjectsService) TaskStatsContext(ctx context.Context, projectId int) (TaskStats, error) {
+	tasks, err := ps.client.Tasks.ListAllContext(ctx, projectId)
+	if err != nil {
+		return TaskStats{}, fmt.Errorf("Couldn't get task stats of project %v: %w\n", projectId, err)
+	}
+	stats := TaskStats{Total: len(tasks)}
+	for _, task := range tasks {
+		if !task.Active {
+			stats.Done++
+		}
+	}
+	return stats, nil
+}
+
+// BurndownPoint is the remaining estimated time at the end of a single day.
+type BurndownPoint struct {
+	Date      time.Time
+	Remaining time.Duration
+}
+
+// Burndown returns one BurndownPoint per day from start to end, giving how
+// much of estimate is left once the project's finished entries up to and
+// including that day are subtracted. Days are in the location of start.
+// Remaining goes negative when the project runs over its estimate.
+func (ps *ProjectsService) Burndown(projectId int, estimate time.Duration, start, end time.Time) ([]BurndownPoint, error) {
+	return ps.BurndownContext(context.Background(), projectId, estimate, start, end)
+}
+
+// BurndownContext is like Burndown with a context.
+func (ps *ProjectsService) BurndownContext(ctx context.Context, projectId int, estimate time.Duration, start, end time.Time) ([]BurndownPoint, error) {
+	entries, err := ps.client.TimeEntries.RangeAllContext(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get burndown: %w\n", err)
+	}
+	loc := start.Location()
+	perDay := map[time.Time]time.Duration{}
+	for _, te := range entries {
+		if te.ProjectId != projectId || te.IsRunning() {
+			continue
+		}
+		perDay[startOfDay(te.Start, loc)] += te.Duration.Duration
+	}
+	points := []BurndownPoint{}
+	remaining := estimate
+	for day := startOfDay(start, loc); !day.After(end); day = day.AddDate(0, 0, 1) {
+		remaining -= perDay[day]
+		points = append(points, BurndownPoint{Date: day, Remaining: remaining})
+	}
+	return points, nil
+}
+
+// startOfDay returns midnight of the day t falls on in loc.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// ForecastCompletion estimates when a project will use up estimate, by
+// extrapolating the daily burn rate over the last lookback days. estimate is
+// the work left at the start of that window, so time tracked within the
+// window counts against it. It returns an error if nothing was tracked on the
+// project in the window.
+func (ps *ProjectsService) ForecastCompletion(projectId int, estimate time.Duration, lookback int) (time.Time, error) {
+	return ps.ForecastCompletionContext(context.Background(), projectId, estimate, lookback)
+}
+
+// ForecastCompletionContext is like ForecastCompletion with a context.
+func (ps *ProjectsService) ForecastCompletionContext(ctx context.Context, projectId int, estimate time.Duration, lookback int) (time.Time, error) {
+	if lookback <= 0 {
+		return time.Time{}, fmt.Errorf("Couldn't forecast completion: lookback must be positive\n")
+	}
+	now := time.Now()
+	entries, err := ps.client.TimeEntries.RangeAllContext(ctx, now.AddDate(0, 0, -lookback), now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Couldn't forecast completion: %w\n", err)
+	}
+	burned := time.Duration(0)
+	for _, te := range entries {
+		if te.ProjectId == projectId && !te.IsRunning() {
+			burned += te.Duration.Duration
+		}
+	}
+	if burned == 0 {
+		return time.Time{}, fmt.Errorf("Couldn't forecast completion: no activity on project %v in the last %v days\n", projectId, lookback)
+	}
+	remaining := estimate - burned
+	if remaining <= 0 {
+		return now, nil
+	}
+	perDay := float64(burned) / float64(lookback)
+	days := float64(remaining) / perDay
+	return now.Add(time.Duration(days * float64(24*time.Hour))), nil
+}
@@ -0,0 +1,94 @@
+package gotoggl
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProjectsByName(t *testing.T) {
+	lists := 0
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		lists++
+		w.Write([]byte(`[
+			{"id":1,"name":"Website"},
+			{"id":2,"name":"website"},
+			{"id":3,"name":"Backend"},
+			{"id":4,"name":"Ops"},
+			{"id":5,"name":"OPS"}
+		]`))
+	})
+	tests := []struct {
+		name string
+		id   int
+		err  string
+	}{
+		{"Website", 1, ""},
+		{"backend", 3, ""},
+		{"ops", 0, "ambiguous"},
+		{"Mobile", 0, "Couldn't find"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := c.Projects.ByName(7, tt.name)
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+			if p.Id != tt.id {
+				t.Errorf("got project %v, want %v", p.Id, tt.id)
+			}
+		})
+	}
+	if _, err := c.Projects.ByName(7, "Mobile"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want ErrNotFound", err)
+	}
+	if lists != 1 {
+		t.Errorf("got %v list requests, want 1", lists)
+	}
+}
+
+func TestReconcileActualTime(t *testing.T) {
+	entries := []TimeEntry{
+		finished(1, at(9, 0), at(11, 0)),
+		finished(1, at(12, 0), at(12, 40)),
+		finished(2, at(13, 0), at(18, 0)),
+		{ProjectId: 1, Start: at(19, 0), Duration: Duration{-1}},
+	}
+	tests := []struct {
+		hours      int
+		consistent bool
+	}{
+		{2, true},
+		{3, true},
+		{1, false},
+		{4, false},
+	}
+	for _, tt := range tests {
+		p := Project{Id: 1, ActualHours: tt.hours}
+		if p.ActualTime() != time.Duration(tt.hours)*time.Hour {
+			t.Errorf("got ActualTime %v for %v hours", p.ActualTime(), tt.hours)
+		}
+		tracked, consistent := p.ReconcileActualTime(entries)
+		if tracked != 2*time.Hour+40*time.Minute || consistent != tt.consistent {
+			t.Errorf("%v hours: got %v, %v", tt.hours, tracked, consistent)
+		}
+	}
+}
+
+func TestTaskStats(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/3/tasks" || r.URL.Query().Get("active") != "both" {
+			t.Errorf("got %v", r.URL)
+		}
+		w.Write([]byte(`{"data":[{"id":1,"active":true},{"id":2,"active":false},{"id":3,"active":false}]}`))
+	})
+	stats, err := c.Projects.TaskStats(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != (TaskStats{Total: 3, Done: 2}) {
+		t.Errorf("got %+v", stats)
+	}
+}
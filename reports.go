@@ -0,0 +1,348 @@
+package gotoggl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Millis is a duration that the reports API sends in milliseconds, unlike
+// the main API which uses seconds, see Duration.
+type Millis struct{ time.Duration }
+
+// UnmarshalJSON loads a millisecond count, which may be quoted like with
+// Duration, into a Go duration. null, which the reports API sends for empty
+// totals, leaves it unchanged.
+func (m *Millis) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	ms, err := strconv.ParseInt(unquoteNumber(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("Couldn't unmarshal toggl.Millis: %w\n", err)
+	}
+	m.Duration = time.Duration(ms) * time.Millisecond
+	return nil
+}
+
+// MarshalJSON writes the duration in whole milliseconds.
+func (m Millis) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, m.Milliseconds(), 10), nil
+}
+
+// ReportsService accesses the reports API
+type ReportsService struct {
+	client *Client
+}
+
+// ReportsDateFormat is the layout of the since and until report parameters.
+// The reports API takes dates as YYYY-MM-DD, not RFC3339 like the main API.
+const ReportsDateFormat = "2006-01-02"
+
+// SummaryParams selects what the summary report covers. WorkspaceId, Since
+// and Until are required. UserAgent defaults to the client's UserAgent.
+// SinceDate and UntilDate, if set, are sent as is instead of Since and
+// Until, for callers that already have YYYY-MM-DD dates.
+//
+// DisplayHours is "decimal" or "minutes", and decides how Toggl rounds the
+// returned totals. Leave it empty for the workspace default. DistinctRates
+// splits each project's items by billing rate, so a project billed at
+// different rates for different people gets one item per rate.
+//
+// Grouping and Subgrouping choose what the report's data and its items are
+// split by, e.g. "projects" and "time_entries", which is what Toggl uses when
+// they are left empty. Grouping by anything but projects leaves the
+// ProjectSummary titles empty, but Items are decoded either way.
+type SummaryParams struct {
+	WorkspaceId   int
+	Since         time.Time
+	Until         time.Time
+	SinceDate     string
+	UntilDate     string
+	UserAgent     string
+	DisplayHours  string
+	DistinctRates bool
+	Grouping      string
+	Subgrouping   string
+}
+
+func (sp SummaryParams) values() url.Values {
+	v := reportValues(sp.WorkspaceId, reportDate(sp.Since, sp.SinceDate), reportDate(sp.Until, sp.UntilDate), sp.UserAgent)
+	if sp.DisplayHours != "" {
+		v.Set("display_hours", sp.DisplayHours)
+	}
+	if sp.DistinctRates {
+		v.Set("distinct_rates", "on")
+	}
+	if sp.Grouping != "" {
+		v.Set("grouping", sp.Grouping)
+	}
+	if sp.Subgrouping != "" {
+		v.Set("subgrouping", sp.Subgrouping)
+	}
+	return v
+}
+
+// SummaryReport contains the data returned by the summary report.
+type SummaryReport struct {
+	TotalGrand      Millis          `json:"total_grand"`
+	TotalBillable   Millis          `json:"total_billable"`
+	TotalCurrencies []CurrencyTotal `json:"total_currencies"`
+	Data            []ProjectSummary
+}
+
+// CurrencyTotal is the billable amount in a single currency.
+type CurrencyTotal struct {
+	Currency string
+	Amount   float64
+}
+
+// ProjectSummary is the time spent on a single project in a summary report.
+type ProjectSummary struct {
+	Id    int
+	Time  Millis
+	Title struct {
+		Client   string
+		Color    string
+		HexColor string `json:"hex_color"`
+		Project  string
+	}
+	TotalCurrencies []CurrencyTotal `json:"total_currencies"`
+	Items           []SummaryItem
+}
+
+// SummaryItem is a row within a project of the summary report, such as a
+// single time entry description with the default subgrouping. With
+// DistinctRates set there is one item per rate. Title is keyed by the
+// subgrouping, e.g. "time_entry", "task" or "user".
+type SummaryItem struct {
+	Title    map[string]string
+	Time     Millis
+	Currency string `json:"cur"`
+	Sum      float64
+	Rate     float64
+}
+
+// summaryTitleKeys are the Title keys Label looks at, in order.
+var summaryTitleKeys = []string{"time_entry", "task", "user", "project", "client"}
+
+// Label returns the item's name for display, whatever the subgrouping.
+func (si SummaryItem) Label() string {
+	for _, key := range summaryTitleKeys {
+		if label := si.Title[key]; label != "" {
+			return label
+		}
+	}
+	return ""
+}
+
+// Summary returns the summary report, which is the time per project like the
+// Toggl dashboard shows.
+func (rs *ReportsService) Summary(params SummaryParams) (SummaryReport, error) {
+	return rs.SummaryContext(context.Background(), params)
+}
+
+// SummaryContext is like Summary with a context.
+func (rs *ReportsService) SummaryContext(ctx context.Context, params SummaryParams) (SummaryReport, error) {
+	report := SummaryReport{}
+	err := rs.client.getReport(ctx, "summary", params.values(), &report)
+	if err != nil {
+		return SummaryReport{}, fmt.Errorf("Couldn't get summary report: %w\n", err)
+	}
+	return report, nil
+}
+
+// DetailedParams selects what the detailed report covers. WorkspaceId, Since
+// and Until are required. UserAgent defaults to the client's UserAgent.
+// SinceDate and UntilDate work like in SummaryParams.
+//
+// ProjectIDs limits the report to entries in those projects. Archived
+// projects are included by the reports API, so old billing periods can be
+// filtered by project too.
+type DetailedParams struct {
+	WorkspaceId int
+	Since       time.Time
+	Until       time.Time
+	SinceDate   string
+	UntilDate   string
+	UserAgent   string
+	ProjectIDs  []int
+}
+
+func (dp DetailedParams) values() url.Values {
+	v := reportValues(dp.WorkspaceId, reportDate(dp.Since, dp.SinceDate), reportDate(dp.Until, dp.UntilDate), dp.UserAgent)
+	if len(dp.ProjectIDs) > 0 {
+		ids := make([]string, len(dp.ProjectIDs))
+		for i, id := range dp.ProjectIDs {
+			ids[i] = strconv.Itoa(id)
+		}
+		v.Set("project_ids", strings.Join(ids, ","))
+	}
+	return v
+}
+
+// DetailedEntry is a single time entry row of the detailed report.
+type DetailedEntry struct {
+	Id          int
+	ProjectId   int `json:"pid"`
+	UserId      int `json:"uid"`
+	Description string
+	Project     string
+	Client      string
+	User        string
+	Start       time.Time
+	End         time.Time
+	Updated     time.Time
+	Dur         Millis
+	IsBillable  bool `json:"is_billable"`
+	Tags        []string
+
+	// BillableAmount is the amount Toggl billed for the entry, in cents of
+	// Currency. It is zero for entries that are not billable or whose
+	// workspace has no rates.
+	BillableAmount int    `json:"billable_amount"`
+	Currency       string `json:"cur"`
+}
+
+// detailedPage is one page of the detailed report.
+type detailedPage struct {
+	TotalCount int `json:"total_count"`
+	PerPage    int `json:"per_page"`
+	Data       []DetailedEntry
+}
+
+// Detailed returns every row of the detailed report. The report is paginated,
+// so this fetches pages until total_count rows have been read.
+func (rs *ReportsService) Detailed(params DetailedParams) ([]DetailedEntry, error) {
+	return rs.DetailedContext(context.Background(), params)
+}
+
+// DetailedContext is like Detailed with a context.
+func (rs *ReportsService) DetailedContext(ctx context.Context, params DetailedParams) ([]DetailedEntry, error) {
+	entries := []DetailedEntry{}
+	v := params.values()
+	for page := 1; ; page++ {
+		v.Set("page", strconv.Itoa(page))
+		resp := detailedPage{}
+		err := rs.client.getReport(ctx, "details", v, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't get detailed report page %v: %w\n", page, err)
+		}
+		entries = append(entries, resp.Data...)
+		if len(resp.Data) == 0 || len(entries) >= resp.TotalCount {
+			return entries, nil
+		}
+	}
+}
+
+// WeeklyParams selects what the weekly report covers. WorkspaceId and Since
+// are required, and the report covers the seven days starting on Since.
+// SinceDate and UserAgent work like in SummaryParams.
+type WeeklyParams struct {
+	WorkspaceId int
+	Since       time.Time
+	SinceDate   string
+	UserAgent   string
+}
+
+func (wp WeeklyParams) values() url.Values {
+	return reportValues(wp.WorkspaceId, reportDate(wp.Since, wp.SinceDate), "", wp.UserAgent)
+}
+
+// WeeklyReport contains the data returned by the weekly report.
+type WeeklyReport struct {
+	TotalGrand    Millis     `json:"total_grand"`
+	TotalBillable Millis     `json:"total_billable"`
+	WeekTotals    WeekTotals `json:"week_totals"`
+	Data          []ProjectWeek
+}
+
+// ProjectWeek is the time spent on a single project in a weekly report.
+type ProjectWeek struct {
+	ProjectId int `json:"pid"`
+	Title     struct {
+		Client   string
+		Color    string
+		HexColor string `json:"hex_color"`
+		Project  string
+	}
+	Totals WeekTotals
+}
+
+// WeekTotals is the time per day of a week, and in total. Days[0] is the
+// first day of the report.
+type WeekTotals struct {
+	Days  [7]time.Duration
+	Total time.Duration
+}
+
+// UnmarshalJSON loads the reports API's array of eight millisecond counts,
+// one per day and then the total, where days without time are null.
+func (wt *WeekTotals) UnmarshalJSON(data []byte) error {
+	totals := []Millis{}
+	if err := json.Unmarshal(data, &totals); err != nil {
+		return fmt.Errorf("Couldn't unmarshal week totals: %w\n", err)
+	}
+	if len(totals) != 8 {
+		return fmt.Errorf("Couldn't unmarshal week totals: got %v values, want 8\n", len(totals))
+	}
+	for i := range wt.Days {
+		wt.Days[i] = totals[i].Duration
+	}
+	wt.Total = totals[7].Duration
+	return nil
+}
+
+// Weekly returns the weekly report, which is the time per project and day
+// like the Toggl weekly dashboard shows.
+func (rs *ReportsService) Weekly(params WeeklyParams) (WeeklyReport, error) {
+	return rs.WeeklyContext(context.Background(), params)
+}
+
+// WeeklyContext is like Weekly with a context.
+func (rs *ReportsService) WeeklyContext(ctx context.Context, params WeeklyParams) (WeeklyReport, error) {
+	report := WeeklyReport{}
+	err := rs.client.getReport(ctx, "weekly", params.values(), &report)
+	if err != nil {
+		return WeeklyReport{}, fmt.Errorf("Couldn't get weekly report: %w\n", err)
+	}
+	return report, nil
+}
+
+// reportDate returns raw if set, and otherwise t in ReportsDateFormat.
+func reportDate(t time.Time, raw string) string {
+	if raw != "" {
+		return raw
+	}
+	return t.Format(ReportsDateFormat)
+}
+
+// reportValues builds the query parameters every report requires. An empty
+// until is left out, for the weekly report.
+func reportValues(workspaceId int, since, until, userAgent string) url.Values {
+	v := url.Values{}
+	v.Set("workspace_id", strconv.Itoa(workspaceId))
+	v.Set("since", since)
+	if until != "" {
+		v.Set("until", until)
+	}
+	if userAgent != "" {
+		v.Set("user_agent", userAgent)
+	}
+	return v
+}
+
+// getReport does a GET to the reports API and unmarshals the result into
+// response. Report responses are not unwrapped, since their data field sits
+// next to totals. The reports API requires a user_agent parameter, which
+// defaults to the client's UserAgent.
+func (c *Client) getReport(ctx context.Context, report string, params url.Values, response interface{}) error {
+	if params.Get("user_agent") == "" {
+		params.Set("user_agent", c.UserAgent)
+	}
+	return c.do(ctx, "GET", c.ReportsURL, report+"?"+params.Encode(), nil, response)
+}
@@ -0,0 +1,223 @@
+package gotoggl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDetailedPagination(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		perPage int
+		pages   int
+	}{
+		{"empty", 0, 50, 1},
+		{"one partial page", 3, 50, 1},
+		{"exactly one page", 50, 50, 1},
+		{"several pages", 120, 50, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := 0
+			c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				pages++
+				if r.URL.Path != "/reports/details" {
+					t.Errorf("got path %v", r.URL.Path)
+				}
+				q := r.URL.Query()
+				if q.Get("since") != "2020-01-01" || q.Get("until") != "2020-01-31" || q.Get("workspace_id") != "7" {
+					t.Errorf("got query %v", q)
+				}
+				page, _ := strconv.Atoi(q.Get("page"))
+				rows := ""
+				for id := (page-1)*tt.perPage + 1; id <= page*tt.perPage && id <= tt.total; id++ {
+					if rows != "" {
+						rows += ","
+					}
+					rows += fmt.Sprintf(`{"id":%d,"dur":1000}`, id)
+				}
+				fmt.Fprintf(w, `{"total_count":%d,"per_page":%d,"data":[%s]}`, tt.total, tt.perPage, rows)
+			})
+			entries, err := c.Reports.Detailed(DetailedParams{
+				WorkspaceId: 7,
+				Since:       time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				Until:       time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != tt.total {
+				t.Errorf("got %v entries, want %v", len(entries), tt.total)
+			}
+			for i, e := range entries {
+				if e.Id != i+1 {
+					t.Fatalf("entry %v has id %v", i, e.Id)
+				}
+			}
+			if pages != tt.pages {
+				t.Errorf("got %v pages, want %v", pages, tt.pages)
+			}
+		})
+	}
+}
+
+func TestDetailedEntryBillableAmount(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total_count":1,"per_page":50,"data":[{"id":1,"is_billable":true,"billable_amount":12550,"cur":"EUR"}]}`))
+	})
+	entries, err := c.Reports.Detailed(DetailedParams{WorkspaceId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].BillableAmount != 12550 || entries[0].Currency != "EUR" {
+		t.Errorf("got amount %v %q", entries[0].BillableAmount, entries[0].Currency)
+	}
+}
+
+func TestSummaryDisplayHours(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("display_hours"); got != "decimal" {
+			t.Errorf("got display_hours %q", got)
+		}
+		w.Write([]byte(`{"total_grand":3600000}`))
+	})
+	report, err := c.Reports.Summary(SummaryParams{WorkspaceId: 1, DisplayHours: "decimal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalGrand.Duration != time.Hour {
+		t.Errorf("got total %v", report.TotalGrand)
+	}
+}
+
+func TestSummaryDistinctRates(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("distinct_rates"); got != "on" {
+			t.Errorf("got distinct_rates %q", got)
+		}
+		w.Write([]byte(`{"total_grand":7200000,"total_currencies":[{"currency":"EUR","amount":150}],"data":[{
+			"id":1,"time":7200000,"title":{"project":"Site"},
+			"total_currencies":[{"currency":"EUR","amount":150}],
+			"items":[
+				{"title":{"time_entry":"Design"},"time":3600000,"cur":"EUR","sum":100,"rate":100},
+				{"title":{"time_entry":"Design"},"time":3600000,"cur":"EUR","sum":50,"rate":50}
+			]}]}`))
+	})
+	report, err := c.Reports.Summary(SummaryParams{WorkspaceId: 1, DistinctRates: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalCurrencies[0].Amount != 150 {
+		t.Errorf("got totals %v", report.TotalCurrencies)
+	}
+	items := report.Data[0].Items
+	if len(items) != 2 || items[0].Rate != 100 || items[1].Sum != 50 || items[1].Title["time_entry"] != "Design" {
+		t.Errorf("got items %+v", items)
+	}
+}
+
+func TestReportDates(t *testing.T) {
+	since := time.Date(2020, 1, 1, 23, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		params SummaryParams
+		since  string
+		until  string
+	}{
+		{"time", SummaryParams{Since: since, Until: since.AddDate(0, 1, 0)}, "2020-01-01", "2020-02-01"},
+		{"raw", SummaryParams{Since: since, SinceDate: "2019-12-31", UntilDate: "2020-01-15"}, "2019-12-31", "2020-01-15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tt.params.values()
+			if v.Get("since") != tt.since || v.Get("until") != tt.until {
+				t.Errorf("got since %q until %q, want %q %q", v.Get("since"), v.Get("until"), tt.since, tt.until)
+			}
+		})
+	}
+}
+
+func TestDetailedProjectIDs(t *testing.T) {
+	v := DetailedParams{WorkspaceId: 1, ProjectIDs: []int{3, 5, 8}}.values()
+	if got := v.Get("project_ids"); got != "3,5,8" {
+		t.Errorf("got project_ids %q", got)
+	}
+	if v := (DetailedParams{WorkspaceId: 1}).values(); v.Has("project_ids") {
+		t.Errorf("got project_ids %q without projects", v.Get("project_ids"))
+	}
+}
+
+func TestMillis(t *testing.T) {
+	tests := []struct {
+		json string
+		want time.Duration
+		err  bool
+	}{
+		{"3600000", time.Hour, false},
+		{"1500", 1500 * time.Millisecond, false},
+		{"null", 0, false},
+		{`"1500"`, 1500 * time.Millisecond, false},
+		{`"x"`, 0, true},
+	}
+	for _, tt := range tests {
+		m := Millis{}
+		err := json.Unmarshal([]byte(tt.json), &m)
+		if (err != nil) != tt.err || m.Duration != tt.want {
+			t.Errorf("%s: got %v, %v", tt.json, m.Duration, err)
+		}
+	}
+	if buf, _ := json.Marshal(Millis{time.Minute}); string(buf) != "60000" {
+		t.Errorf("got %s, want 60000", buf)
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/reports/weekly" || q.Get("since") != "2020-01-06" || q.Has("until") {
+			t.Errorf("got %v with %v", r.URL.Path, q)
+		}
+		w.Write([]byte(`{
+			"total_grand":9000000,
+			"week_totals":[3600000,null,null,null,5400000,null,null,9000000],
+			"data":[{"pid":3,"title":{"project":"Site"},"totals":[3600000,null,null,null,5400000,null,null,9000000]}]
+		}`))
+	})
+	report, err := c.Reports.Weekly(WeeklyParams{WorkspaceId: 1, Since: time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := WeekTotals{Days: [7]time.Duration{time.Hour, 0, 0, 0, 90 * time.Minute}, Total: 150 * time.Minute}
+	if report.WeekTotals != want || report.Data[0].Totals != want || report.Data[0].ProjectId != 3 {
+		t.Errorf("got %+v", report)
+	}
+	if err := json.Unmarshal([]byte(`[1,2,3]`), &WeekTotals{}); err == nil {
+		t.Error("got no error for three totals")
+	}
+}
+
+func TestSummaryGrouping(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("grouping") != "projects" || q.Get("subgrouping") != "tasks" {
+			t.Errorf("got query %v", q)
+		}
+		w.Write([]byte(`{"data":[{"id":1,"time":5400000,"title":{"project":"Site","client":null},"items":[
+			{"title":{"task":"Design"},"time":3600000},
+			{"title":{"task":"Build"},"time":1800000}
+		]}]}`))
+	})
+	report, err := c.Reports.Summary(SummaryParams{WorkspaceId: 1, Grouping: "projects", Subgrouping: "tasks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := report.Data[0].Items
+	if len(items) != 2 || items[0].Label() != "Design" || items[1].Time.Duration != 30*time.Minute {
+		t.Errorf("got items %+v", items)
+	}
+}
@@ -0,0 +1,186 @@
+package gotoggl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// EntryResolver looks up the names of the projects and clients of a set of
+// time entries. Nothing is fetched until a name is first asked for, and then
+// the projects, or clients, of every workspace the entries are in are fetched
+// at once and cached, so resolving many entries costs one request per
+// workspace rather than one per entry. Tags need no resolving, since Toggl
+// returns them by name.
+type EntryResolver struct {
+	client  *Client
+	entries []TimeEntry
+
+	mu       sync.Mutex
+	projects map[int]Project
+	clients  map[int]TogglClient
+}
+
+// NewEntryResolver returns an EntryResolver for entries.
+func (c *Client) NewEntryResolver(entries []TimeEntry) *EntryResolver {
+	return &EntryResolver{client: c, entries: entries}
+}
+
+// workspaceIds returns the distinct workspaces of the entries.
+func (er *EntryResolver) workspaceIds() []int {
+	seen := map[int]bool{}
+	wids := []int{}
+	for _, te := range er.entries {
+		if te.WorkspaceId != 0 && !seen[te.WorkspaceId] {
+			seen[te.WorkspaceId] = true
+			wids = append(wids, te.WorkspaceId)
+		}
+	}
+	return wids
+}
+
+// loadProjects fetches the projects of every workspace of the entries, unless
+// that was already done. er.mu must be held.
+func (er *EntryResolver) loadProjects(ctx context.Context) error {
+	if er.projects != nil {
+		return nil
+	}
+	projects := map[int]Project{}
+	for _, wid := range er.workspaceIds() {
+		list, err := er.client.Projects.CachedListContext(ctx, wid)
+		if err != nil {
+			return err
+		}
+		for _, p := range list {
+			projects[p.Id] = p
+		}
+	}
+	er.projects = projects
+	return nil
+}
+
+// loadClients is like loadProjects for clients.
+func (er *EntryResolver) loadClients(ctx context.Context) error {
+	if er.clients != nil {
+		return nil
+	}
+	clients := map[int]TogglClient{}
+	for _, wid := range er.workspaceIds() {
+		list, err := er.client.Clients.ListContext(ctx, wid)
+		if err != nil {
+			return err
+		}
+		for _, c := range list {
+			clients[c.Id] = c
+		}
+	}
+	er.clients = clients
+	return nil
+}
+
+// Project returns the project of te. Projects that are not in the list of
+// their workspace, such as those of entries outside the resolver's set, are
+// fetched one by one and cached too. An entry without a project returns the
+// zero Project.
+func (er *EntryResolver) Project(te TimeEntry) (Project, error) {
+	return er.ProjectContext(context.Background(), te)
+}
+
+// ProjectContext is like Project with a context.
+func (er *EntryResolver) ProjectContext(ctx context.Context, te TimeEntry) (Project, error) {
+	if te.ProjectId == 0 {
+		return Project{}, nil
+	}
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if err := er.loadProjects(ctx); err != nil {
+		return Project{}, fmt.Errorf("Couldn't resolve project %v: %w\n", te.ProjectId, err)
+	}
+	if p, ok := er.projects[te.ProjectId]; ok {
+		return p, nil
+	}
+	p, err := er.client.Projects.GetContext(ctx, te.ProjectId)
+	if err != nil {
+		return Project{}, fmt.Errorf("Couldn't resolve project %v: %w\n", te.ProjectId, err)
+	}
+	er.projects[p.Id] = p
+	return p, nil
+}
+
+// ProjectName returns the name of the project of te, or "" if it has none.
+func (er *EntryResolver) ProjectName(te TimeEntry) (string, error) {
+	return er.ProjectNameContext(context.Background(), te)
+}
+
+// ProjectNameContext is like ProjectName with a context.
+func (er *EntryResolver) ProjectNameContext(ctx context.Context, te TimeEntry) (string, error) {
+	p, err := er.ProjectContext(ctx, te)
+	return p.Name, err
+}
+
+// ClientName returns the name of the client of te's project, or "" if the
+// entry has no project or the project has no client.
+func (er *EntryResolver) ClientName(te TimeEntry) (string, error) {
+	return er.ClientNameContext(context.Background(), te)
+}
+
+// ClientNameContext is like ClientName with a context.
+func (er *EntryResolver) ClientNameContext(ctx context.Context, te TimeEntry) (string, error) {
+	p, err := er.ProjectContext(ctx, te)
+	if err != nil || p.ClientId == 0 {
+		return "", err
+	}
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if err := er.loadClients(ctx); err != nil {
+		return "", fmt.Errorf("Couldn't resolve client %v: %w\n", p.ClientId, err)
+	}
+	if c, ok := er.clients[p.ClientId]; ok {
+		return c.Name, nil
+	}
+	c, err := er.client.Clients.GetContext(ctx, p.ClientId)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't resolve client %v: %w\n", p.ClientId, err)
+	}
+	er.clients[c.Id] = c
+	return c.Name, nil
+}
+
+// EnrichedTimeEntry is a time entry together with the names of its project
+// and client, and the project's hex color.
+type EnrichedTimeEntry struct {
+	TimeEntry
+	ProjectName  string
+	ClientName   string
+	ProjectColor string // Like "#4dc3ff", or "" without a project
+}
+
+// EnrichEntries returns the entries with their project and client names and
+// project colors filled in. Projects and clients are resolved with an
+// EntryResolver, so each is fetched at most once.
+func (c *Client) EnrichEntries(entries []TimeEntry) ([]EnrichedTimeEntry, error) {
+	return c.EnrichEntriesContext(context.Background(), entries)
+}
+
+// EnrichEntriesContext is like EnrichEntries with a context.
+func (c *Client) EnrichEntriesContext(ctx context.Context, entries []TimeEntry) ([]EnrichedTimeEntry, error) {
+	resolver := c.NewEntryResolver(entries)
+	enriched := make([]EnrichedTimeEntry, len(entries))
+	for i, te := range entries {
+		project, err := resolver.ProjectContext(ctx, te)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't enrich time entries: %w\n", err)
+		}
+		client, err := resolver.ClientNameContext(ctx, te)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't enrich time entries: %w\n", err)
+		}
+		enriched[i] = EnrichedTimeEntry{
+			TimeEntry:    te,
+			ProjectName:  project.Name,
+			ClientName:   client,
+			ProjectColor: project.HexColorOrIndex(),
+		}
+	}
+	return enriched, nil
+}
@@ -0,0 +1,113 @@
+package gotoggl
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestEntryResolver(t *testing.T) {
+	requests := map[string]int{}
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/workspaces/1/projects":
+			w.Write([]byte(`[{"id":10,"name":"Website","cid":100},{"id":11,"name":"Internal"}]`))
+		case "/workspaces/1/clients":
+			w.Write([]byte(`[{"id":100,"name":"Acme"}]`))
+		case "/projects/12":
+			w.Write([]byte(`{"data":{"id":12,"name":"Archived","cid":101}}`))
+		case "/clients/101":
+			w.Write([]byte(`{"data":{"id":101,"name":"Old client"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	entries := []TimeEntry{
+		{WorkspaceId: 1, ProjectId: 10},
+		{WorkspaceId: 1, ProjectId: 11},
+		{WorkspaceId: 1, ProjectId: 12},
+		{WorkspaceId: 1},
+	}
+	er := c.NewEntryResolver(entries)
+	if len(requests) != 0 {
+		t.Fatalf("got requests %v before any name was asked for", requests)
+	}
+	tests := []struct {
+		entry   TimeEntry
+		project string
+		client  string
+	}{
+		{entries[0], "Website", "Acme"},
+		{entries[1], "Internal", ""},
+		{entries[2], "Archived", "Old client"},
+		{entries[3], "", ""},
+		{entries[0], "Website", "Acme"},
+	}
+	for _, tt := range tests {
+		project, err := er.ProjectName(tt.entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := er.ClientName(tt.entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if project != tt.project || client != tt.client {
+			t.Errorf("project %v: got %q, %q, want %q, %q", tt.entry.ProjectId, project, client, tt.project, tt.client)
+		}
+	}
+	for path, n := range requests {
+		if n != 1 {
+			t.Errorf("got %v requests to %v, want 1", n, path)
+		}
+	}
+}
+
+func TestEnrichEntries(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/workspaces/1/projects":
+			w.Write([]byte(`[{"id":10,"name":"Website","cid":100,"hex_color":"#06aaf5"},{"id":11,"name":"Internal","color":"2"}]`))
+		case "/workspaces/1/clients":
+			w.Write([]byte(`[{"id":100,"name":"Acme"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	entries := []TimeEntry{
+		{Id: 1, WorkspaceId: 1, ProjectId: 10},
+		{Id: 2, WorkspaceId: 1, ProjectId: 11},
+		{Id: 3, WorkspaceId: 1},
+	}
+	enriched, err := c.EnrichEntries(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []EnrichedTimeEntry{
+		{TimeEntry: entries[0], ProjectName: "Website", ClientName: "Acme", ProjectColor: "#06aaf5"},
+		{TimeEntry: entries[1], ProjectName: "Internal", ProjectColor: "#df7baa"},
+		{TimeEntry: entries[2]},
+	}
+	if !reflect.DeepEqual(enriched, want) {
+		t.Errorf("got %+v\nwant %+v", enriched, want)
+	}
+}
+
+func TestHexColorOrIndex(t *testing.T) {
+	tests := []struct {
+		project Project
+		want    string
+	}{
+		{Project{HexColor: "#06aaf5", Color: "3"}, "#06aaf5"},
+		{Project{Color: "0"}, "#4dc3ff"},
+		{Project{Color: "14"}, "#999999"},
+		{Project{Color: "15"}, ""},
+		{Project{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.project.HexColorOrIndex(); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.project, got, tt.want)
+		}
+	}
+}
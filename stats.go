@@ -0,0 +1,465 @@
+package gotoggl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AvgEntryDuration returns the mean duration of finished entries per project
+// id. Running entries are skipped.
+func AvgEntryDuration(entries []TimeEntry) map[int]time.Duration {
+	totals := map[int]time.Duration{}
+	counts := map[int]int{}
+	for _, te := range entries {
+		if te.IsRunning() {
+			continue
+		}
+		totals[te.ProjectId] += te.Duration.Duration
+		counts[te.ProjectId]++
+	}
+	avgs := map[int]time.Duration{}
+	for pid, total := range totals {
+		avgs[pid] = total / time.Duration(counts[pid])
+	}
+	return avgs
+}
+
+// SplitAcrossMidnight returns the entries with every finished entry that
+// crosses midnight in loc split into one segment per day. Each segment keeps
+// the original entry's fields, with Start and Stop clipped to the day and the
+// duration prorated by the segment's share of the entry. Running entries and
+// entries within a single day are returned unchanged.
+func SplitAcrossMidnight(entries []TimeEntry, loc *time.Location) []TimeEntry {
+	split := []TimeEntry{}
+	for _, te := range entries {
+		span := te.Stop.Sub(te.Start)
+		if te.IsRunning() || span <= 0 {
+			split = append(split, te)
+			continue
+		}
+		for segStart := te.Start; segStart.Before(te.Stop); {
+			segStop := startOfDay(segStart, loc).AddDate(0, 0, 1)
+			if segStop.After(te.Stop) {
+				segStop = te.Stop
+			}
+			seg := te
+			seg.Start = segStart
+			seg.Stop = segStop
+			seg.Duration.Duration = time.Duration(float64(te.Duration.Duration) * float64(segStop.Sub(segStart)) / float64(span))
+			split = append(split, seg)
+			segStart = segStop
+		}
+	}
+	return split
+}
+
+// LongestStreak returns the longest run of consecutive calendar days in loc
+// with at least one entry started on them, along with midnight of the first
+// and last day of the run. It returns zero values for no entries.
+func LongestStreak(entries []TimeEntry, loc *time.Location) (days int, start, end time.Time) {
+	seen := map[time.Time]bool{}
+	for _, te := range entries {
+		seen[startOfDay(te.Start, loc)] = true
+	}
+	sorted := []time.Time{}
+	for day := range seen {
+		sorted = append(sorted, day)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	run := 0
+	for i, day := range sorted {
+		if i > 0 && sorted[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > days {
+			days, start, end = run, sorted[i-run+1], day
+		}
+	}
+	return days, start, end
+}
+
+// TextSummary returns a one-line recap of the entries started today in loc,
+// such as "Today: 6h30m across 2 projects — project 123 4h30m, project 456 2h".
+// Running entries count up to now. Projects are listed by id, longest first.
+func TextSummary(entries []TimeEntry, loc *time.Location) string {
+	today := startOfDay(time.Now(), loc)
+	perProject := map[int]time.Duration{}
+	total := time.Duration(0)
+	for _, te := range entries {
+		if !startOfDay(te.Start, loc).Equal(today) {
+			continue
+		}
+		d := te.Duration.Duration
+		if te.IsRunning() {
+			d = time.Since(te.Start)
+		}
+		perProject[te.ProjectId] += d
+		total += d
+	}
+	pids := []int{}
+	for pid := range perProject {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool {
+		if perProject[pids[i]] != perProject[pids[j]] {
+			return perProject[pids[i]] > perProject[pids[j]]
+		}
+		return pids[i] < pids[j]
+	})
+	parts := []string{}
+	for _, pid := range pids {
+		name := "no project"
+		if pid != 0 {
+			name = fmt.Sprintf("project %d", pid)
+		}
+		parts = append(parts, name+" "+formatDuration(perProject[pid]))
+	}
+	noun := "projects"
+	if len(pids) == 1 {
+		noun = "project"
+	}
+	summary := fmt.Sprintf("Today: %s across %d %s", formatDuration(total), len(pids), noun)
+	if len(parts) > 0 {
+		summary += " — " + strings.Join(parts, ", ")
+	}
+	return summary
+}
+
+// formatDuration formats d in whole minutes as e.g. "6h30m", "4h" or "45m".
+func formatDuration(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	switch {
+	case h > 0 && m > 0:
+		return fmt.Sprintf("%dh%dm", h, m)
+	case h > 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dm", m)
+	}
+}
+
+// ProjectRate is the hourly billing rate of a project.
+type ProjectRate struct {
+	Rate     float64
+	Currency string
+}
+
+// BillableConverted sums the billable amount of the finished billable entries,
+// priced with the hourly rate of each entry's project and converted into the
+// target currency by convert. Entries whose project has no rate are skipped.
+// Amounts already in the target currency are not passed to convert.
+func BillableConverted(entries []TimeEntry, rates map[int]ProjectRate, convert func(amount float64, from, to string) float64, target string) float64 {
+	total := 0.0
+	for _, te := range entries {
+		rate, ok := rates[te.ProjectId]
+		if !ok || !te.Billable || te.IsRunning() {
+			continue
+		}
+		amount := te.Duration.Hours() * rate.Rate
+		if rate.Currency != target {
+			amount = convert(amount, rate.Currency, target)
+		}
+		total += amount
+	}
+	return total
+}
+
+// GroupByDescription sums the durations of finished entries per description,
+// with surrounding whitespace trimmed. Running entries are skipped.
+func GroupByDescription(entries []TimeEntry) map[string]time.Duration {
+	return groupByDescription(entries, strings.TrimSpace)
+}
+
+// GroupByDescriptionFold is like GroupByDescription but ignores case. The
+// descriptions are lowercased in the returned map.
+func GroupByDescriptionFold(entries []TimeEntry) map[string]time.Duration {
+	return groupByDescription(entries, func(s string) string {
+		return strings.ToLower(strings.TrimSpace(s))
+	})
+}
+
+func groupByDescription(entries []TimeEntry, key func(string) string) map[string]time.Duration {
+	totals := map[string]time.Duration{}
+	for _, te := range entries {
+		if te.IsRunning() {
+			continue
+		}
+		totals[key(te.Description)] += te.Duration.Duration
+	}
+	return totals
+}
+
+// Gap is an untracked period.
+type Gap struct {
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+}
+
+// LargeGaps returns the untracked periods longer than minGap within working
+// hours, for each day in loc that has at least one entry. Only the clock
+// times of workStart and workEnd are used, so the working hours are the same
+// every day. Running entries count as tracked up to now.
+func LargeGaps(entries []TimeEntry, minGap time.Duration, workStart, workEnd time.Time, loc *time.Location) []Gap {
+	days := map[time.Time]bool{}
+	for _, te := range entries {
+		days[startOfDay(te.Start, loc)] = true
+	}
+	sortedDays := []time.Time{}
+	for day := range days {
+		sortedDays = append(sortedDays, day)
+	}
+	sort.Slice(sortedDays, func(i, j int) bool { return sortedDays[i].Before(sortedDays[j]) })
+
+	sorted := append([]TimeEntry{}, entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	gaps := []Gap{}
+	for _, day := range sortedDays {
+		from := atClock(day, workStart.In(loc))
+		to := atClock(day, workEnd.In(loc))
+		cursor := from
+		for _, te := range sorted {
+			stop := te.Stop
+			if te.IsRunning() {
+				stop = time.Now()
+			}
+			if !stop.After(cursor) || !te.Start.Before(to) {
+				continue
+			}
+			if te.Start.After(cursor) && te.Start.Sub(cursor) > minGap {
+				gaps = append(gaps, Gap{Start: cursor, End: te.Start, Duration: te.Start.Sub(cursor)})
+			}
+			cursor = stop
+		}
+		if to.After(cursor) && to.Sub(cursor) > minGap {
+			gaps = append(gaps, Gap{Start: cursor, End: to, Duration: to.Sub(cursor)})
+		}
+	}
+	return gaps
+}
+
+// atClock returns the time on day with the hour, minute and second of clock.
+func atClock(day, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, day.Location())
+}
+
+// DailyEarnings returns the billable earnings per day in loc, keyed by date
+// as "2006-01-02". Entries crossing midnight are split between the days.
+// Only finished billable entries whose project has a rate are counted, and
+// currencies are not converted.
+func DailyEarnings(entries []TimeEntry, rates map[int]ProjectRate, loc *time.Location) map[string]float64 {
+	earnings := map[string]float64{}
+	for _, te := range SplitAcrossMidnight(entries, loc) {
+		rate, ok := rates[te.ProjectId]
+		if !ok || !te.Billable || te.IsRunning() {
+			continue
+		}
+		earnings[te.Start.In(loc).Format("2006-01-02")] += te.Duration.Hours() * rate.Rate
+	}
+	return earnings
+}
+
+// Metrics is a snapshot of entry totals, meant to be exported as gauges or
+// counters by whatever metrics system the caller uses. Durations only count
+// finished entries.
+type Metrics struct {
+	Tracked           time.Duration
+	Billable          time.Duration
+	ProjectDurations  map[int]time.Duration
+	Entries           int
+	RunningEntries    int
+	ProjectEntryCount map[int]int
+}
+
+// MetricsSnapshot computes Metrics over the given entries.
+func MetricsSnapshot(entries []TimeEntry) Metrics {
+	m := Metrics{
+		ProjectDurations:  map[int]time.Duration{},
+		ProjectEntryCount: map[int]int{},
+	}
+	for _, te := range entries {
+		m.Entries++
+		m.ProjectEntryCount[te.ProjectId]++
+		if te.IsRunning() {
+			m.RunningEntries++
+			continue
+		}
+		m.Tracked += te.Duration.Duration
+		m.ProjectDurations[te.ProjectId] += te.Duration.Duration
+		if te.Billable {
+			m.Billable += te.Duration.Duration
+		}
+	}
+	return m
+}
+
+// NormalizeTagCase returns copies of the entries with every tag replaced by
+// its canonical form. canonical is keyed by lowercased tag, e.g.
+// {"meeting": "Meeting"}. Tags without a canonical form are kept as is, and
+// tags that become duplicates are dropped. The input entries are not changed.
+func NormalizeTagCase(entries []TimeEntry, canonical map[string]string) []TimeEntry {
+	normalized := make([]TimeEntry, len(entries))
+	for i, te := range entries {
+		if te.Tags != nil {
+			tags := []string{}
+			seen := map[string]bool{}
+			for _, tag := range te.Tags {
+				if c, ok := canonical[strings.ToLower(tag)]; ok {
+					tag = c
+				}
+				if !seen[tag] {
+					seen[tag] = true
+					tags = append(tags, tag)
+				}
+			}
+			te.Tags = tags
+		}
+		normalized[i] = te
+	}
+	return normalized
+}
+
+// TagCaseVariants finds tags used with more than one casing. The result is
+// keyed by lowercased tag and lists the variants seen, sorted.
+func TagCaseVariants(entries []TimeEntry) map[string][]string {
+	seen := map[string]map[string]bool{}
+	for _, te := range entries {
+		for _, tag := range te.Tags {
+			key := strings.ToLower(tag)
+			if seen[key] == nil {
+				seen[key] = map[string]bool{}
+			}
+			seen[key][tag] = true
+		}
+	}
+	variants := map[string][]string{}
+	for key, tags := range seen {
+		if len(tags) < 2 {
+			continue
+		}
+		for tag := range tags {
+			variants[key] = append(variants[key], tag)
+		}
+		sort.Strings(variants[key])
+	}
+	return variants
+}
+
+// InLocation returns copies of the entries with Start and Stop converted to
+// loc, for display. A zero Stop, as on a running entry, stays zero.
+func InLocation(entries []TimeEntry, loc *time.Location) []TimeEntry {
+	converted := make([]TimeEntry, len(entries))
+	for i, te := range entries {
+		te.Start = te.Start.In(loc)
+		if !te.Stop.IsZero() {
+			te.Stop = te.Stop.In(loc)
+		}
+		converted[i] = te
+	}
+	return converted
+}
+
+// HoursOfDayDistribution spreads the duration of each finished entry over the
+// hours of the day in loc that it spans, so an entry from 9:30 to 11:00 adds
+// 30 minutes to hour 9 and an hour to hour 10. Running entries are skipped.
+func HoursOfDayDistribution(entries []TimeEntry, loc *time.Location) [24]time.Duration {
+	hours := [24]time.Duration{}
+	for _, te := range entries {
+		span := te.Stop.Sub(te.Start)
+		if te.IsRunning() || span <= 0 {
+			continue
+		}
+		for cur := te.Start.In(loc); cur.Before(te.Stop); {
+			next := time.Date(cur.Year(), cur.Month(), cur.Day(), cur.Hour()+1, 0, 0, 0, loc)
+			if !next.After(cur) {
+				next = cur.Add(time.Hour)
+			}
+			if next.After(te.Stop) {
+				next = te.Stop
+			}
+			hours[cur.Hour()] += time.Duration(float64(te.Duration.Duration) * float64(next.Sub(cur)) / float64(span))
+			cur = next.In(loc)
+		}
+	}
+	return hours
+}
+
+// BillableSplit is time divided into billable and non-billable.
+type BillableSplit struct {
+	Billable    time.Duration
+	NonBillable time.Duration
+}
+
+// BillableByClient sums finished entries per client id, split into billable
+// and non-billable time. projectClient maps project ids to client ids.
+// Entries whose project has no client are summed under client id 0.
+func BillableByClient(entries []TimeEntry, projectClient map[int]int) map[int]BillableSplit {
+	splits := map[int]BillableSplit{}
+	for _, te := range entries {
+		if te.IsRunning() {
+			continue
+		}
+		cid := projectClient[te.ProjectId]
+		split := splits[cid]
+		if te.Billable {
+			split.Billable += te.Duration.Duration
+		} else {
+			split.NonBillable += te.Duration.Duration
+		}
+		splits[cid] = split
+	}
+	return splits
+}
+
+// WeekGrid lays out finished entries as a timesheet of projects by days, for
+// the seven days starting on the day of weekStart in loc. Entries crossing
+// midnight are split between the days, and time outside the week is left
+// out. projects lists the project ids in the grid in ascending order.
+func WeekGrid(entries []TimeEntry, weekStart time.Time, loc *time.Location) (projects []int, grid map[int][7]time.Duration) {
+	first := startOfDay(weekStart, loc)
+	grid = map[int][7]time.Duration{}
+	for _, te := range SplitAcrossMidnight(entries, loc) {
+		if te.IsRunning() {
+			continue
+		}
+		day := startOfDay(te.Start, loc)
+		for i := 0; i < 7; i++ {
+			if first.AddDate(0, 0, i).Equal(day) {
+				row := grid[te.ProjectId]
+				row[i] += te.Duration.Duration
+				grid[te.ProjectId] = row
+				break
+			}
+		}
+	}
+	projects = []int{}
+	for pid := range grid {
+		projects = append(projects, pid)
+	}
+	sort.Ints(projects)
+	return projects, grid
+}
+
+// FindTooLong returns the entries longer than max. Running entries are
+// included once they have been running for longer than max, which catches
+// timers that were left on overnight.
+func FindTooLong(entries []TimeEntry, max time.Duration) []TimeEntry {
+	tooLong := []TimeEntry{}
+	for _, te := range entries {
+		d := te.Duration.Duration
+		if te.IsRunning() {
+			d = time.Since(te.Start)
+		}
+		if d > max {
+			tooLong = append(tooLong, te)
+		}
+	}
+	return tooLong
+}
@@ -0,0 +1,239 @@
+package gotoggl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAvgEntryDuration(t *testing.T) {
+	entries := []TimeEntry{
+		finished(1, at(9, 0), at(10, 0)),
+		finished(1, at(10, 0), at(10, 30)),
+		finished(2, at(11, 0), at(11, 20)),
+		{ProjectId: 2, Start: at(12, 0), Duration: Duration{-1}},
+	}
+	want := map[int]time.Duration{1: 45 * time.Minute, 2: 20 * time.Minute}
+	if got := AvgEntryDuration(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSplitAcrossMidnight(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2020, 1, d, hour, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name  string
+		entry TimeEntry
+		want  [][2]time.Time
+	}{
+		{"within a day", finished(1, day(1, 9), day(1, 17)), [][2]time.Time{{day(1, 9), day(1, 17)}}},
+		{"across midnight", finished(1, day(1, 22), day(2, 2)), [][2]time.Time{
+			{day(1, 22), day(2, 0)},
+			{day(2, 0), day(2, 2)},
+		}},
+		{"across two midnights", finished(1, day(1, 12), day(3, 12)), [][2]time.Time{
+			{day(1, 12), day(2, 0)},
+			{day(2, 0), day(3, 0)},
+			{day(3, 0), day(3, 12)},
+		}},
+		{"ends at midnight", finished(1, day(1, 22), day(2, 0)), [][2]time.Time{{day(1, 22), day(2, 0)}}},
+		{"running", TimeEntry{Start: day(1, 22), Duration: Duration{-1}}, [][2]time.Time{{day(1, 22), {}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segs := SplitAcrossMidnight([]TimeEntry{tt.entry}, time.UTC)
+			got := [][2]time.Time{}
+			total := time.Duration(0)
+			for _, seg := range segs {
+				got = append(got, [2]time.Time{seg.Start, seg.Stop})
+				total += seg.Duration.Duration
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got segments %v, want %v", got, tt.want)
+			}
+			if total != tt.entry.Duration.Duration {
+				t.Errorf("segments add up to %v, want %v", total, tt.entry.Duration.Duration)
+			}
+		})
+	}
+}
+
+func TestLongestStreak(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC) }
+	entries := []TimeEntry{}
+	for _, d := range []int{1, 2, 4, 5, 6, 6, 8} {
+		entries = append(entries, finished(1, day(d).Add(9*time.Hour), day(d).Add(10*time.Hour)))
+	}
+	days, start, end := LongestStreak(entries, time.UTC)
+	if days != 3 || !start.Equal(day(4)) || !end.Equal(day(6)) {
+		t.Errorf("got %v days from %v to %v", days, start, end)
+	}
+	if days, _, _ := LongestStreak(nil, time.UTC); days != 0 {
+		t.Errorf("got %v days for no entries", days)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{45 * time.Minute, "45m"},
+		{4 * time.Hour, "4h"},
+		{6*time.Hour + 30*time.Minute + 59*time.Second, "6h30m"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestGroupByDescription(t *testing.T) {
+	entries := []TimeEntry{
+		finished(1, at(9, 0), at(10, 0)),
+		finished(1, at(10, 0), at(10, 30)),
+		finished(1, at(11, 0), at(11, 15)),
+	}
+	entries[0].Description = "Email"
+	entries[1].Description = " email "
+	entries[2].Description = "Email "
+	want := map[string]time.Duration{"Email": 75 * time.Minute, "email": 30 * time.Minute}
+	if got := GroupByDescription(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByDescription got %v, want %v", got, want)
+	}
+	want = map[string]time.Duration{"email": 105 * time.Minute}
+	if got := GroupByDescriptionFold(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByDescriptionFold got %v, want %v", got, want)
+	}
+}
+
+func TestLargeGaps(t *testing.T) {
+	entries := []TimeEntry{
+		finished(1, at(9, 30), at(10, 0)),
+		finished(1, at(10, 10), at(12, 0)),
+		finished(1, at(11, 0), at(11, 30)),
+		finished(1, at(13, 0), at(16, 0)),
+	}
+	got := LargeGaps(entries, 15*time.Minute, at(9, 0), at(17, 0), time.UTC)
+	want := []Gap{
+		{at(9, 0), at(9, 30), 30 * time.Minute},
+		{at(12, 0), at(13, 0), time.Hour},
+		{at(16, 0), at(17, 0), time.Hour},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDailyEarnings(t *testing.T) {
+	late := finished(1, at(22, 0), at(22, 0).Add(4*time.Hour))
+	late.Billable = true
+	unbillable := finished(1, at(9, 0), at(10, 0))
+	noRate := finished(2, at(9, 0), at(10, 0))
+	noRate.Billable = true
+	rates := map[int]ProjectRate{1: {Rate: 100, Currency: "EUR"}}
+	got := DailyEarnings([]TimeEntry{late, unbillable, noRate}, rates, time.UTC)
+	want := map[string]float64{"2020-01-01": 200, "2020-01-02": 200}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeTagCase(t *testing.T) {
+	entries := []TimeEntry{{Tags: []string{"meeting", "Meeting", "MEETING", "other"}}, {}}
+	got := NormalizeTagCase(entries, map[string]string{"meeting": "Meeting"})
+	if want := []string{"Meeting", "other"}; !reflect.DeepEqual(got[0].Tags, want) {
+		t.Errorf("got tags %v, want %v", got[0].Tags, want)
+	}
+	if got[1].Tags != nil {
+		t.Errorf("got tags %v for an untagged entry", got[1].Tags)
+	}
+	if entries[0].Tags[0] != "meeting" {
+		t.Errorf("input entry was changed")
+	}
+	variants := TagCaseVariants(entries)
+	if want := map[string][]string{"meeting": {"MEETING", "Meeting", "meeting"}}; !reflect.DeepEqual(variants, want) {
+		t.Errorf("got variants %v, want %v", variants, want)
+	}
+}
+
+func TestHoursOfDayDistribution(t *testing.T) {
+	hours := HoursOfDayDistribution([]TimeEntry{finished(1, at(9, 30), at(11, 0))}, time.UTC)
+	want := [24]time.Duration{9: 30 * time.Minute, 10: time.Hour}
+	if hours != want {
+		t.Errorf("got %v, want %v", hours, want)
+	}
+}
+
+func TestBillableByClient(t *testing.T) {
+	billable := finished(1, at(9, 0), at(10, 0))
+	billable.Billable = true
+	entries := []TimeEntry{billable, finished(1, at(10, 0), at(10, 30)), finished(2, at(11, 0), at(12, 0))}
+	got := BillableByClient(entries, map[int]int{1: 10})
+	want := map[int]BillableSplit{
+		10: {Billable: time.Hour, NonBillable: 30 * time.Minute},
+		0:  {NonBillable: time.Hour},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWeekGrid(t *testing.T) {
+	monday := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+	hour := func(days, hours int) time.Time {
+		return monday.AddDate(0, 0, days).Add(time.Duration(hours) * time.Hour)
+	}
+	tests := []struct {
+		name     string
+		entries  []TimeEntry
+		projects []int
+		grid     map[int][7]time.Duration
+	}{
+		{"empty", nil, []int{}, map[int][7]time.Duration{}},
+		{"two projects", []TimeEntry{
+			finished(2, hour(0, 9), hour(0, 11)),
+			finished(1, hour(0, 13), hour(0, 14)),
+			finished(2, hour(4, 9), hour(4, 10)),
+		}, []int{1, 2}, map[int][7]time.Duration{
+			1: {time.Hour},
+			2: {2 * time.Hour, 0, 0, 0, time.Hour},
+		}},
+		{"split across midnight", []TimeEntry{
+			finished(1, hour(1, 23), hour(2, 1)),
+		}, []int{1}, map[int][7]time.Duration{
+			1: {0, time.Hour, time.Hour},
+		}},
+		{"outside the week", []TimeEntry{
+			finished(1, hour(-1, 23), hour(0, 1)),
+			finished(1, hour(6, 23), hour(7, 2)),
+		}, []int{1}, map[int][7]time.Duration{
+			1: {time.Hour, 0, 0, 0, 0, 0, time.Hour},
+		}},
+		{"running", []TimeEntry{
+			{ProjectId: 1, Start: hour(0, 9), Duration: Duration{-1}},
+		}, []int{}, map[int][7]time.Duration{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects, grid := WeekGrid(tt.entries, monday.Add(15*time.Hour), time.UTC)
+			if !reflect.DeepEqual(projects, tt.projects) {
+				t.Errorf("got projects %v, want %v", projects, tt.projects)
+			}
+			if !reflect.DeepEqual(grid, tt.grid) {
+				t.Errorf("got grid %v, want %v", grid, tt.grid)
+			}
+		})
+	}
+}
+
+func TestFindTooLong(t *testing.T) {
+	long := finished(1, at(9, 0), at(18, 0))
+	left := TimeEntry{Id: 2, Start: time.Now().Add(-13 * time.Hour), Duration: Duration{-1}}
+	got := FindTooLong([]TimeEntry{finished(1, at(9, 0), at(10, 0)), long, left}, 8*time.Hour)
+	if len(got) != 2 || got[1].Id != 2 {
+		t.Errorf("got %v", got)
+	}
+}
@@ -0,0 +1,54 @@
+package gotoggl
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Tag contains the data returned for a single tag.
+type Tag struct {
+	Id          int
+	WorkspaceId int `json:"wid"`
+	Name        string
+	At          time.Time
+}
+
+// TagsService accesses /tags
+type TagsService struct {
+	client *Client
+}
+
+// List returns the tags in a workspace
+func (ts *TagsService) List(workspaceId int) ([]Tag, error) {
+	return ts.ListContext(context.Background(), workspaceId)
+}
+
+// ListContext is like List with a context.
+func (ts *TagsService) ListContext(ctx context.Context, workspaceId int) ([]Tag, error) {
+	tags := []Tag{}
+	err := ts.client.getData(ctx, fmt.Sprintf("workspaces/%d/tags", workspaceId), &tags)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get tags: %w\n", err)
+	}
+	return tags, nil
+}
+
+// Create creates a tag in a workspace
+func (ts *TagsService) Create(workspaceId int, name string) (Tag, error) {
+	return ts.CreateContext(context.Background(), workspaceId, name)
+}
+
+// CreateContext is like Create with a context.
+func (ts *TagsService) CreateContext(ctx context.Context, workspaceId int, name string) (Tag, error) {
+	body := map[string]interface{}{"tag": map[string]interface{}{
+		"wid":  workspaceId,
+		"name": name,
+	}}
+	tag := Tag{}
+	err := ts.client.doData(ctx, "POST", "tags", body, &tag)
+	if err != nil {
+		return Tag{}, fmt.Errorf("Couldn't create tag %v: %w\n", name, err)
+	}
+	return tag, nil
+}
@@ -0,0 +1,87 @@
+package gotoggl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Task contains the data returned for a single task. Tasks belong to
+// projects and only exist in premium workspaces.
+type Task struct {
+	Id               int
+	Name             string
+	ProjectId        int      `json:"pid"`
+	WorkspaceId      int      `json:"wid"`
+	UserId           int      `json:"uid"`
+	EstimatedSeconds Duration `json:"estimated_seconds"`
+	Active           bool
+	At               time.Time
+}
+
+// TasksService accesses /tasks. Toggl answers 403 Forbidden for tasks in a
+// free workspace, so its methods return an error wrapping both
+// ErrPremiumRequired and the *APIError for any 403. That is only an
+// assumption: an invalid API token, or a project the user can't access,
+// gets a 403 too, so check the APIError when it matters.
+type TasksService struct {
+	client *Client
+}
+
+// List returns the active tasks of a project. Toggl leaves out done tasks,
+// see ListAll. A 403 is assumed to mean a free workspace, see TasksService.
+func (ts *TasksService) List(projectId int) ([]Task, error) {
+	return ts.ListContext(context.Background(), projectId)
+}
+
+// ListContext is like List with a context.
+func (ts *TasksService) ListContext(ctx context.Context, projectId int) ([]Task, error) {
+	return ts.list(ctx, fmt.Sprintf("projects/%d/tasks", projectId))
+}
+
+// ListAll returns every task of a project, both active and done.
+func (ts *TasksService) ListAll(projectId int) ([]Task, error) {
+	return ts.ListAllContext(context.Background(), projectId)
+}
+
+// ListAllContext is like ListAll with a context.
+func (ts *TasksService) ListAllContext(ctx context.Context, projectId int) ([]Task, error) {
+	return ts.list(ctx, fmt.Sprintf("projects/%d/tasks?active=both", projectId))
+}
+
+func (ts *TasksService) list(ctx context.Context, path string) ([]Task, error) {
+	tasks := []Task{}
+	err := ts.client.getData(ctx, path, &tasks)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get tasks: %w\n", tasksError(err))
+	}
+	return tasks, nil
+}
+
+// Get returns details of a single task. A 403 is assumed to mean a free
+// workspace, see TasksService.
+func (ts *TasksService) Get(id int) (Task, error) {
+	return ts.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get with a context.
+func (ts *TasksService) GetContext(ctx context.Context, id int) (Task, error) {
+	task := Task{}
+	err := ts.client.getData(ctx, fmt.Sprintf("tasks/%d", id), &task)
+	if err != nil {
+		return Task{}, fmt.Errorf("Couldn't get task %v: %w\n", id, tasksError(err))
+	}
+	return task, nil
+}
+
+// tasksError turns the 403 Toggl answers for tasks in a free workspace into
+// an error wrapping ErrPremiumRequired, keeping the *APIError in the chain.
+func tasksError(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %w", ErrPremiumRequired, err)
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package gotoggl
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTasks(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/3/tasks":
+			w.Write([]byte(`{"data":[{"id":1,"name":"Design","pid":3,"wid":7,"estimated_seconds":7200,"active":true}]}`))
+		case "/tasks/1":
+			w.Write([]byte(`{"data":{"id":1,"name":"Design","pid":3}}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	})
+	tasks, err := c.Tasks.List(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].EstimatedSeconds.Duration != 2*time.Hour || tasks[0].ProjectId != 3 || !tasks[0].Active {
+		t.Errorf("got %+v", tasks)
+	}
+	task, err := c.Tasks.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "Design" {
+		t.Errorf("got %+v", task)
+	}
+	_, err = c.Tasks.List(4)
+	if !errors.Is(err, ErrPremiumRequired) {
+		t.Errorf("got error %v for a free workspace, want ErrPremiumRequired", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("got error %v, want the 403 APIError kept", err)
+	}
+}
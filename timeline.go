@@ -0,0 +1,80 @@
+package gotoggl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TimelineBlock is a stretch of a day in a DayTimeline. Start and End are
+// offsets from midnight. Gap blocks are untracked time and have no project.
+type TimelineBlock struct {
+	Start       time.Duration
+	End         time.Duration
+	Gap         bool
+	Description string
+	ProjectId   int
+	ProjectName string
+	Color       string // Hex color of the project, like "#06aaf5"
+}
+
+// DayTimeline returns the blocks of the day of date in loc, ordered by start.
+// Every tracked stretch is a block with its project's name and color, and the
+// untracked time between them, from midnight to midnight, are gap blocks.
+// Entries crossing midnight are clipped to the day, and a running entry
+// counts as tracked up to now. Overlapping entries give overlapping blocks.
+func (c *Client) DayTimeline(entries []TimeEntry, date time.Time, loc *time.Location) ([]TimelineBlock, error) {
+	return c.DayTimelineContext(context.Background(), entries, date, loc)
+}
+
+// DayTimelineContext is like DayTimeline with a context.
+func (c *Client) DayTimelineContext(ctx context.Context, entries []TimeEntry, date time.Time, loc *time.Location) ([]TimelineBlock, error) {
+	day := startOfDay(date, loc)
+	next := day.AddDate(0, 0, 1)
+	segs := []TimeEntry{}
+	for _, te := range SplitAcrossMidnight(entries, loc) {
+		if startOfDay(te.Start, loc).Equal(day) {
+			segs = append(segs, te)
+		}
+	}
+	if len(segs) == 0 {
+		return []TimelineBlock{{Start: 0, End: next.Sub(day), Gap: true}}, nil
+	}
+
+	resolver := c.NewEntryResolver(segs)
+	blocks := []TimelineBlock{}
+	for _, te := range segs {
+		stop := te.Stop
+		if te.IsRunning() {
+			stop = time.Now()
+		}
+		if stop.After(next) {
+			stop = next
+		}
+		project, err := resolver.ProjectContext(ctx, te)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't build timeline: %w\n", err)
+		}
+		blocks = append(blocks, TimelineBlock{
+			Start:       te.Start.Sub(day),
+			End:         stop.Sub(day),
+			Description: te.Description,
+			ProjectId:   te.ProjectId,
+			ProjectName: project.Name,
+			Color:       project.HexColorOrIndex(),
+		})
+	}
+	// LargeGaps only takes clock times, so the day is asked for up to its
+	// last second and a gap ending there is stretched to midnight.
+	lastSecond := next.Add(-time.Second)
+	for _, gap := range LargeGaps(segs, 0, day, lastSecond, loc) {
+		end := gap.End
+		if end.Equal(atClock(day, lastSecond)) {
+			end = next
+		}
+		blocks = append(blocks, TimelineBlock{Start: gap.Start.Sub(day), End: end.Sub(day), Gap: true})
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].Start < blocks[j].Start })
+	return blocks, nil
+}
@@ -0,0 +1,48 @@
+package gotoggl
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDayTimeline(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":10,"name":"Website","hex_color":"#06aaf5"}]`))
+	})
+	h := func(hours, minutes int) time.Duration {
+		return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	}
+	previous := finished(10, at(0, 0).Add(-time.Hour), at(1, 0))
+	previous.Description = "Deploy"
+	entries := []TimeEntry{
+		previous,
+		finished(0, at(9, 0), at(10, 30)),
+		finished(10, at(11, 0), at(12, 0)),
+		finished(10, at(0, 0).AddDate(0, 0, 1), at(1, 0).AddDate(0, 0, 1)),
+	}
+	blocks, err := c.DayTimeline(entries, at(15, 0), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TimelineBlock{
+		{Start: 0, End: h(1, 0), Description: "Deploy", ProjectId: 10, ProjectName: "Website", Color: "#06aaf5"},
+		{Start: h(1, 0), End: h(9, 0), Gap: true},
+		{Start: h(9, 0), End: h(10, 30)},
+		{Start: h(10, 30), End: h(11, 0), Gap: true},
+		{Start: h(11, 0), End: h(12, 0), ProjectId: 10, ProjectName: "Website", Color: "#06aaf5"},
+		{Start: h(12, 0), End: h(24, 0), Gap: true},
+	}
+	if !reflect.DeepEqual(blocks, want) {
+		t.Errorf("got %+v\nwant %+v", blocks, want)
+	}
+
+	blocks, err = c.DayTimeline(nil, at(15, 0), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []TimelineBlock{{End: h(24, 0), Gap: true}}; !reflect.DeepEqual(blocks, want) {
+		t.Errorf("got %+v for an empty day", blocks)
+	}
+}
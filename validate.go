@@ -0,0 +1,70 @@
+package gotoggl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Validate checks that the entry could be sent to Toggl as a new entry: it
+// must have a start time, a workspace or project, and a stop time that is
+// not before the start.
+func (te TimeEntry) Validate() error {
+	if te.Start.IsZero() {
+		return fmt.Errorf("Time entry has no start time\n")
+	}
+	if te.WorkspaceId == 0 && te.ProjectId == 0 {
+		return fmt.Errorf("Time entry needs a workspace or project\n")
+	}
+	if !te.Stop.IsZero() && te.Stop.Before(te.Start) {
+		return fmt.Errorf("Time entry stops before it starts\n")
+	}
+	if !te.Stop.IsZero() && te.Duration.Duration < 0 {
+		return fmt.Errorf("Time entry is stopped but has a negative duration\n")
+	}
+	return nil
+}
+
+// BatchError is a problem with the entry at Index of a batch.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (be BatchError) Error() string {
+	return fmt.Sprintf("entry %d: %v", be.Index, be.Err)
+}
+
+// ValidateBatch runs Validate on every entry and also reports finished
+// entries whose times overlap an earlier one. It returns every problem found,
+// ordered by index, or nil if there are none.
+func ValidateBatch(entries []TimeEntry) []BatchError {
+	var errs []BatchError
+	timed := []int{}
+	for i, te := range entries {
+		if err := te.Validate(); err != nil {
+			errs = append(errs, BatchError{Index: i, Err: err})
+			continue
+		}
+		if !te.Stop.IsZero() {
+			timed = append(timed, i)
+		}
+	}
+	sort.SliceStable(timed, func(a, b int) bool {
+		return entries[timed[a]].Start.Before(entries[timed[b]].Start)
+	})
+	// latest is the entry seen so far that stops last, which is the one a
+	// later-starting entry would overlap.
+	for k, latest := 1, 0; k < len(timed); k++ {
+		if entries[timed[k-1]].Stop.After(entries[timed[latest]].Stop) {
+			latest = k - 1
+		}
+		if entries[timed[k]].Start.Before(entries[timed[latest]].Stop) {
+			errs = append(errs, BatchError{
+				Index: timed[k],
+				Err:   fmt.Errorf("Time entry overlaps entry %d\n", timed[latest]),
+			})
+		}
+	}
+	sort.SliceStable(errs, func(a, b int) bool { return errs[a].Index < errs[b].Index })
+	return errs
+}
@@ -0,0 +1,99 @@
+package gotoggl
+
+import (
+	"testing"
+	"time"
+)
+
+// at returns 2020-01-01 at hour:min UTC.
+func at(hour, min int) time.Time {
+	return time.Date(2020, 1, 1, hour, min, 0, 0, time.UTC)
+}
+
+// finished returns a finished entry in project pid from start to stop.
+func finished(pid int, start, stop time.Time) TimeEntry {
+	return TimeEntry{
+		WorkspaceId: 1,
+		ProjectId:   pid,
+		Start:       start,
+		Stop:        stop,
+		Duration:    Duration{stop.Sub(start)},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry TimeEntry
+		ok    bool
+	}{
+		{"finished", finished(1, at(9, 0), at(10, 0)), true},
+		{"running", TimeEntry{WorkspaceId: 1, Start: at(9, 0), Duration: Duration{-1}}, true},
+		{"project only", TimeEntry{ProjectId: 1, Start: at(9, 0)}, true},
+		{"no start", TimeEntry{WorkspaceId: 1}, false},
+		{"no workspace or project", TimeEntry{Start: at(9, 0)}, false},
+		{"stops before start", finished(1, at(10, 0), at(9, 0)), false},
+		{"stopped with negative duration", TimeEntry{WorkspaceId: 1, Start: at(9, 0), Stop: at(10, 0), Duration: Duration{-1}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.Validate()
+			if (err == nil) != tt.ok {
+				t.Errorf("got error %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []TimeEntry
+		want    []int
+	}{
+		{"empty", nil, nil},
+		{"back to back", []TimeEntry{
+			finished(1, at(9, 0), at(10, 0)),
+			finished(1, at(10, 0), at(11, 0)),
+		}, nil},
+		{"overlap", []TimeEntry{
+			finished(1, at(9, 0), at(10, 0)),
+			finished(1, at(9, 30), at(11, 0)),
+		}, []int{1}},
+		{"out of order", []TimeEntry{
+			finished(1, at(9, 30), at(11, 0)),
+			finished(1, at(9, 0), at(10, 0)),
+		}, []int{0}},
+		{"overlaps a long earlier entry", []TimeEntry{
+			finished(1, at(8, 0), at(12, 0)),
+			finished(1, at(9, 0), at(9, 30)),
+			finished(1, at(11, 0), at(11, 30)),
+		}, []int{1, 2}},
+		{"invalid and overlapping", []TimeEntry{
+			{Start: at(9, 0)},
+			finished(1, at(9, 0), at(10, 0)),
+			finished(1, at(9, 59), at(10, 30)),
+		}, []int{0, 2}},
+		{"running entries do not overlap", []TimeEntry{
+			finished(1, at(9, 0), at(10, 0)),
+			{WorkspaceId: 1, Start: at(9, 30), Duration: Duration{-1}},
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateBatch(tt.entries)
+			got := []int{}
+			for _, err := range errs {
+				got = append(got, err.Index)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got errors %v, want indexes %v", errs, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got errors %v, want indexes %v", errs, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package gotoggl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Workspace contains the data returned for a single workspace.
+type Workspace struct {
+	Id                int
+	Name              string
+	Premium           bool
+	Admin             bool
+	DefaultHourlyRate float64 `json:"default_hourly_rate"`
+	DefaultCurrency   string  `json:"default_currency"`
+	At                time.Time
+
+	// Rounding is the default report rounding: -1 rounds down, 0 to the
+	// nearest and 1 up, to multiples of RoundingMinutes.
+	Rounding        int
+	RoundingMinutes int `json:"rounding_minutes"`
+}
+
+// WorkspacesService accesses /workspaces
+type WorkspacesService struct {
+	client *Client
+}
+
+// List returns the workspaces the current user belongs to
+func (ws *WorkspacesService) List() ([]Workspace, error) {
+	return ws.ListContext(context.Background())
+}
+
+// ListContext is like List with a context.
+func (ws *WorkspacesService) ListContext(ctx context.Context) ([]Workspace, error) {
+	workspaces := []Workspace{}
+	err := ws.client.getData(ctx, "workspaces", &workspaces)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get workspaces: %w\n", err)
+	}
+	return workspaces, nil
+}
+
+// Get returns details of a single workspace
+func (ws *WorkspacesService) Get(id int) (Workspace, error) {
+	return ws.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get with a context.
+func (ws *WorkspacesService) GetContext(ctx context.Context, id int) (Workspace, error) {
+	workspace := Workspace{}
+	err := ws.client.getData(ctx, fmt.Sprintf("workspaces/%d", id), &workspace)
+	if err != nil {
+		return Workspace{}, fmt.Errorf("Couldn't get workspace %v: %w\n", id, err)
+	}
+	return workspace, nil
+}
+
+// WorkspaceExport is the configuration of a workspace, as returned by
+// Export. Tasks is nil for a workspace without tasks, which is every free
+// workspace.
+type WorkspaceExport struct {
+	WorkspaceId int
+	Projects    []Project
+	Clients     []TogglClient
+	Tags        []Tag
+	Tasks       []Task
+}
+
+// Export gathers the projects, clients, tags and tasks of a workspace, for
+// backups and migrations. Done tasks are included. The lists are fetched
+// concurrently, within the client's MaxConcurrency limit. If any of them
+// fails, the returned error joins the errors of every failed list.
+func (ws *WorkspacesService) Export(workspaceId int) (WorkspaceExport, error) {
+	return ws.ExportContext(context.Background(), workspaceId)
+}
+
+// ExportContext is like Export with a context.
+func (ws *WorkspacesService) ExportContext(ctx context.Context, workspaceId int) (WorkspaceExport, error) {
+	c := ws.client
+	export := WorkspaceExport{WorkspaceId: workspaceId}
+	errs := make([]error, 3)
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		export.Projects, errs[0] = c.Projects.ListContext(ctx, workspaceId)
+	}()
+	go func() {
+		defer wg.Done()
+		export.Clients, errs[1] = c.Clients.ListContext(ctx, workspaceId)
+	}()
+	go func() {
+		defer wg.Done()
+		export.Tags, errs[2] = c.Tags.ListContext(ctx, workspaceId)
+	}()
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return WorkspaceExport{}, fmt.Errorf("Couldn't export workspace %v: %w\n", workspaceId, err)
+	}
+
+	tasks := make([][]Task, len(export.Projects))
+	errs = make([]error, len(export.Projects))
+	for i, p := range export.Projects {
+		wg.Add(1)
+		go func(i, projectId int) {
+			defer wg.Done()
+			tasks[i], errs[i] = c.Tasks.ListAllContext(ctx, projectId)
+		}(i, p.Id)
+	}
+	wg.Wait()
+	for i := range errs {
+		if errors.Is(errs[i], ErrPremiumRequired) {
+			errs[i] = nil
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return WorkspaceExport{}, fmt.Errorf("Couldn't export tasks of workspace %v: %w\n", workspaceId, err)
+	}
+	for _, projectTasks := range tasks {
+		export.Tasks = append(export.Tasks, projectTasks...)
+	}
+	return export, nil
+}
+
+// NewClientWithWorkspaces creates a new Toggl API client like NewClient, and
+// then calls LoadWorkspaces so that CachedWorkspaces and DefaultWorkspaceId
+// are ready to use.
+func NewClientWithWorkspaces(apiKey string) (*Client, error) {
+	c := NewClient(apiKey)
+	if err := c.LoadWorkspaces(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadWorkspaces fetches the current user with related data and stores the
+// user's workspaces in CachedWorkspaces and the default workspace id in
+// DefaultWorkspaceId. Call it again to refresh them.
+func (c *Client) LoadWorkspaces() error {
+	return c.LoadWorkspacesContext(context.Background())
+}
+
+// LoadWorkspacesContext is like LoadWorkspaces with a context.
+func (c *Client) LoadWorkspacesContext(ctx context.Context) error {
+	related := struct {
+		DefaultWorkspaceId int `json:"default_wid"`
+		Workspaces         []Workspace
+	}{}
+	err := c.getData(ctx, "me?with_related_data=true", &related)
+	if err != nil {
+		return fmt.Errorf("Couldn't load workspaces: %w\n", err)
+	}
+	c.CachedWorkspaces = related.Workspaces
+	c.DefaultWorkspaceId = related.DefaultWorkspaceId
+	return nil
+}
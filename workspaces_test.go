@@ -0,0 +1,54 @@
+package gotoggl
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	premium := true
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/workspaces/1/projects":
+			w.Write([]byte(`[{"id":10,"name":"Website","cid":100},{"id":11,"name":"Internal"}]`))
+		case "/workspaces/1/clients":
+			w.Write([]byte(`[{"id":100,"name":"Acme"}]`))
+		case "/workspaces/1/tags":
+			w.Write([]byte(`[{"id":1000,"name":"meeting"}]`))
+		case "/projects/10/tasks", "/projects/11/tasks":
+			if !premium {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if r.URL.Query().Get("active") != "both" {
+				t.Errorf("got %v, want done tasks too", r.URL)
+			}
+			if r.URL.Path == "/projects/10/tasks" {
+				w.Write([]byte(`{"data":[{"id":5,"pid":10,"active":false}]}`))
+			} else {
+				w.Write([]byte(`{"data":null}`))
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	export, err := c.Workspaces.Export(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.WorkspaceId != 1 || len(export.Projects) != 2 || len(export.Clients) != 1 || len(export.Tags) != 1 {
+		t.Errorf("got %+v", export)
+	}
+	if len(export.Tasks) != 1 || export.Tasks[0].Id != 5 {
+		t.Errorf("got tasks %+v", export.Tasks)
+	}
+
+	premium = false
+	export, err = c.Workspaces.Export(1)
+	if err != nil {
+		t.Fatalf("got error %v for a free workspace", err)
+	}
+	if len(export.Projects) != 2 || export.Tasks != nil {
+		t.Errorf("got %+v for a free workspace", export)
+	}
+}